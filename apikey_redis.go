@@ -0,0 +1,267 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Default values for APIKeyRedisConfig
+const (
+	DefaultRedisAPIKeyKey = "api_key"
+	DefaultAPIKeyPrefix   = "ak_"
+)
+
+// Define the format of API keys and limits for Redis access.
+const (
+	apiKeyIDSize           = 8           // Random bytes of the key ID used for lookup
+	apiKeySecretSize       = 32          // Random bytes of the key secret
+	apiKeySecretSeparator  = "."         // Separator between the key ID and secret
+	apiKeyRedisLimitGetTx  = 100         // Max Redis keys fetched per call
+	apiKeyLastUsedInterval = time.Minute // Min interval between updates of LastUsedAt
+)
+
+var (
+	ErrAPIKeyInvalid  = errors.New("invalid api key")     // Error for unknown, revoked or malformed API keys.
+	ErrAPIKeyExpired  = errors.New("api key has expired") // Error for API keys past their expiry.
+	ErrAPIKeyNotFound = errors.New("api key not found")   // Error for when API key is not found.
+)
+
+// APIKeyRedisConfig is used to configure API keys stored in Redis.
+type APIKeyRedisConfig struct {
+	KeyPrefix   string       // Prefix for the Redis keys, defaults to DefaultRedisAPIKeyKey
+	TokenPrefix string       // Prefix of the generated API keys, defaults to DefaultAPIKeyPrefix
+	Client      *RedisClient // Redis client instance to use for accessing the server
+}
+
+// APIKey represents the metadata of an API key. The key itself is never stored.
+type APIKey struct {
+	ID         string   `json:"id"`                  // ID of the key, part of the key used for lookup.
+	Owner      string   `json:"owner"`               // Owner of the key, e.g. a service or partner ID.
+	Scopes     []string `json:"scopes,omitempty"`    // Scopes granted to the key.
+	ExpiresAt  int64    `json:"expiresAt,omitempty"` // Unix time when the key expires, zero for keys that never expire.
+	CreatedAt  int64    `json:"createdAt"`           // Unix time when the key was created or last rotated.
+	LastUsedAt int64    `json:"lastUsedAt"`          // Unix time when the key was last verified.
+}
+
+// apiKeyRecord is an API key stored in Redis together with the hash of its secret.
+type apiKeyRecord struct {
+	APIKey
+	Hash string `json:"hash"` // SHA256 hash of the key secret.
+}
+
+// APIKeyHandler represents an interface for managing API keys.
+type APIKeyHandler interface {
+	Create(owner string, scopes []string, expiresAt int64) (string, APIKey, error)
+	Rotate(id string) (string, APIKey, error)
+	Revoke(id string) error
+	Verify(key string) (APIKey, error)
+	Get(id string) (APIKey, error)
+	ListByOwner(owner string) ([]APIKey, error)
+}
+
+// APIKeyRedisHandler is used to handle API keys stored in Redis.
+type APIKeyRedisHandler struct {
+	prefixKey   string
+	tokenPrefix string
+	client      *RedisClient
+}
+
+// NewAPIKeyHandler creates a new Redis API key handler using the provided configuration.
+func (RedisUtil) NewAPIKeyHandler(config APIKeyRedisConfig) APIKeyHandler {
+	config.KeyPrefix = strings.TrimSpace(config.KeyPrefix)
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = DefaultRedisAPIKeyKey
+	}
+	if config.TokenPrefix == "" {
+		config.TokenPrefix = DefaultAPIKeyPrefix
+	}
+	return &APIKeyRedisHandler{
+		prefixKey:   config.KeyPrefix,
+		tokenPrefix: config.TokenPrefix,
+		client:      config.Client,
+	}
+}
+
+// Create creates a new API key for the owner and returns the key, which is only available once.
+func (h *APIKeyRedisHandler) Create(owner string, scopes []string, expiresAt int64) (string, APIKey, error) {
+	b := make([]byte, apiKeyIDSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", APIKey{}, err
+	}
+	apiKey := APIKey{
+		ID:        hex.EncodeToString(b),
+		Owner:     owner,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+	return h.save(apiKey)
+}
+
+// Rotate replaces the secret of an API key, keeping its ID and metadata.
+// The previous key is no longer valid.
+func (h *APIKeyRedisHandler) Rotate(id string) (string, APIKey, error) {
+	apiKey, err := h.Get(id)
+	if err != nil {
+		return "", APIKey{}, err
+	}
+	return h.save(apiKey)
+}
+
+// Revoke deletes an API key.
+func (h *APIKeyRedisHandler) Revoke(id string) error {
+	return h.client.Del(context.TODO(), h.getKey(id), h.getLastUsedKey(id)).Err()
+}
+
+// Verify verifies an API key in constant time and returns its metadata, updating LastUsedAt.
+// LastUsedAt is stored in its own key, so that verifying never rewrites the key record.
+func (h *APIKeyRedisHandler) Verify(key string) (APIKey, error) {
+	id, secret, ok := strings.Cut(strings.TrimPrefix(key, h.tokenPrefix), apiKeySecretSeparator)
+	if !ok || !strings.HasPrefix(key, h.tokenPrefix) || id == "" || secret == "" {
+		return APIKey{}, ErrAPIKeyInvalid
+	}
+	record, err := h.get(id)
+	if err == ErrAPIKeyNotFound {
+		return APIKey{}, ErrAPIKeyInvalid
+	}
+	if err != nil {
+		return APIKey{}, err
+	}
+	if subtle.ConstantTimeCompare([]byte(String.SHA256(secret)), []byte(record.Hash)) != 1 {
+		return APIKey{}, ErrAPIKeyInvalid
+	}
+	now := time.Now().Unix()
+	if record.ExpiresAt > 0 && now >= record.ExpiresAt {
+		return APIKey{}, ErrAPIKeyExpired
+	}
+	if now-record.LastUsedAt >= int64(apiKeyLastUsedInterval.Seconds()) {
+		record.LastUsedAt = now
+		if err := h.client.Set(context.TODO(), h.getLastUsedKey(id), now, h.ttl(record.APIKey)).Err(); err != nil {
+			return APIKey{}, err
+		}
+	}
+	return record.APIKey, nil
+}
+
+// Get returns the metadata of an API key.
+func (h *APIKeyRedisHandler) Get(id string) (APIKey, error) {
+	record, err := h.get(id)
+	return record.APIKey, err
+}
+
+// ListByOwner returns the API keys of the owner.
+func (h *APIKeyRedisHandler) ListByOwner(owner string) ([]APIKey, error) {
+	var cursor uint64
+	ctx := context.TODO()
+	apiKeys := []APIKey{}
+	for {
+		var err error
+		var keys []string
+		keys, cursor, err = h.client.Scan(ctx, cursor, h.getKey("*"), apiKeyRedisLimitGetTx).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(keys) > 0 {
+			values, err := h.client.MGet(ctx, keys...).Result()
+			if err != nil {
+				return nil, err
+			}
+			for _, v := range values {
+				s, ok := v.(string)
+				if !ok {
+					continue
+				}
+				record := apiKeyRecord{}
+				if err := json.Unmarshal([]byte(s), &record); err == nil && record.Owner == owner {
+					apiKeys = append(apiKeys, record.APIKey)
+				}
+			}
+		}
+		if cursor == 0 {
+			break
+		}
+	}
+	for i := 0; i < len(apiKeys); i += apiKeyRedisLimitGetTx {
+		batch := apiKeys[i:Min(i+apiKeyRedisLimitGetTx, len(apiKeys))]
+		keys := make([]string, len(batch))
+		for j, apiKey := range batch {
+			keys[j] = h.getLastUsedKey(apiKey.ID)
+		}
+		values, err := h.client.MGet(ctx, keys...).Result()
+		if err != nil {
+			return nil, err
+		}
+		for j, v := range values {
+			if s, ok := v.(string); ok {
+				batch[j].LastUsedAt = Max(batch[j].LastUsedAt, parseLastUsedAt(s))
+			}
+		}
+	}
+	return apiKeys, nil
+}
+
+// save generates a new secret for the API key and stores it with the hash of the secret.
+func (h *APIKeyRedisHandler) save(apiKey APIKey) (string, APIKey, error) {
+	b := make([]byte, apiKeySecretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", APIKey{}, err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(b)
+	apiKey.CreatedAt = time.Now().Unix()
+	record := apiKeyRecord{APIKey: apiKey, Hash: String.SHA256(secret)}
+	if err := h.client.SetStruct(h.getKey(apiKey.ID), record, h.ttl(apiKey)); err != nil {
+		return "", APIKey{}, err
+	}
+	return h.tokenPrefix + apiKey.ID + apiKeySecretSeparator + secret, apiKey, nil
+}
+
+// ttl returns the expiration of the Redis keys of an API key, zero for keys that never expire.
+func (h *APIKeyRedisHandler) ttl(apiKey APIKey) time.Duration {
+	if apiKey.ExpiresAt == 0 {
+		return 0
+	}
+	return time.Duration(Max(1, apiKey.ExpiresAt-time.Now().Unix())) * time.Second
+}
+
+// get retrieves an API key record from Redis together with its last used time.
+func (h *APIKeyRedisHandler) get(id string) (apiKeyRecord, error) {
+	record := apiKeyRecord{}
+	if err := h.client.GetStruct(h.getKey(id), &record); err != nil {
+		if err == redis.Nil {
+			return record, ErrAPIKeyNotFound
+		}
+		return record, err
+	}
+	lastUsedAt, err := h.client.Get(context.TODO(), h.getLastUsedKey(id)).Result()
+	if err != nil && err != redis.Nil {
+		return record, err
+	}
+	record.LastUsedAt = Max(record.LastUsedAt, parseLastUsedAt(lastUsedAt))
+	return record, nil
+}
+
+// getKey returns the Redis key of an API key.
+func (h *APIKeyRedisHandler) getKey(id string) string {
+	return h.prefixKey + ":" + id
+}
+
+// getLastUsedKey returns the Redis key of the last used time of an API key,
+// outside of the key pattern of the API keys.
+func (h *APIKeyRedisHandler) getLastUsedKey(id string) string {
+	return h.prefixKey + "_last_used:" + id
+}
+
+// parseLastUsedAt parses a stored last used time, zero when it's missing or invalid.
+func parseLastUsedAt(s string) int64 {
+	lastUsedAt, _ := strconv.ParseInt(s, 10, 64)
+	return lastUsedAt
+}
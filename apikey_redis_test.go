@@ -0,0 +1,104 @@
+package utils_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+func newTestAPIKeyHandler(t *testing.T) utils.APIKeyHandler {
+	_, client := newTestRedisClient(t)
+	return utils.Redis.NewAPIKeyHandler(utils.APIKeyRedisConfig{Client: client})
+}
+
+func TestAPIKeyRedisHandler_CreateVerify(t *testing.T) {
+	handler := newTestAPIKeyHandler(t)
+	key, apiKey, err := handler.Create("partner-1", []string{"orders:read"}, 0)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, utils.DefaultAPIKeyPrefix+apiKey.ID+"."))
+	assert.Equal(t, "partner-1", apiKey.Owner)
+
+	verified, err := handler.Verify(key)
+	assert.NoError(t, err)
+	assert.Equal(t, apiKey.ID, verified.ID)
+	assert.Equal(t, []string{"orders:read"}, verified.Scopes)
+	assert.NotZero(t, verified.LastUsedAt)
+	stored, _ := handler.Get(apiKey.ID)
+	assert.Equal(t, verified.LastUsedAt, stored.LastUsedAt)
+
+	for _, invalid := range []string{"", "invalid", key + "x", utils.DefaultAPIKeyPrefix + "unknown.secret", strings.TrimPrefix(key, utils.DefaultAPIKeyPrefix)} {
+		_, err = handler.Verify(invalid)
+		assert.ErrorIs(t, err, utils.ErrAPIKeyInvalid, invalid)
+	}
+}
+
+func TestAPIKeyRedisHandler_Expired(t *testing.T) {
+	handler := newTestAPIKeyHandler(t)
+	key, _, err := handler.Create("partner-1", nil, time.Now().Add(-time.Second).Unix())
+	assert.NoError(t, err)
+	_, err = handler.Verify(key)
+	assert.ErrorIs(t, err, utils.ErrAPIKeyExpired)
+}
+
+func TestAPIKeyRedisHandler_RotateRevoke(t *testing.T) {
+	handler := newTestAPIKeyHandler(t)
+	key, apiKey, _ := handler.Create("partner-1", nil, 0)
+	rotated, rotatedKey, err := handler.Rotate(apiKey.ID)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, rotated)
+	assert.Equal(t, apiKey.ID, rotatedKey.ID)
+	_, err = handler.Verify(key)
+	assert.ErrorIs(t, err, utils.ErrAPIKeyInvalid)
+	_, err = handler.Verify(rotated)
+	assert.NoError(t, err)
+
+	assert.NoError(t, handler.Revoke(apiKey.ID))
+	_, err = handler.Verify(rotated)
+	assert.ErrorIs(t, err, utils.ErrAPIKeyInvalid)
+	_, _, err = handler.Rotate(apiKey.ID)
+	assert.ErrorIs(t, err, utils.ErrAPIKeyNotFound)
+}
+
+func TestAPIKeyRedisHandler_ListByOwner(t *testing.T) {
+	handler := newTestAPIKeyHandler(t)
+	_, first, _ := handler.Create("partner-1", nil, 0)
+	_, second, _ := handler.Create("partner-1", nil, 0)
+	_, _, _ = handler.Create("partner-2", nil, 0)
+	list, err := handler.ListByOwner("partner-1")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{first.ID, second.ID}, []string{list[0].ID, list[1].ID})
+}
+
+func TestAPIKeyRedisHandler_VerifyRotate(t *testing.T) {
+	handler := newTestAPIKeyHandler(t)
+	key, apiKey, _ := handler.Create("partner-1", nil, 0)
+	for i := 0; i < 20; i++ {
+		var rotated string
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, _ = handler.Verify(key)
+		}()
+		go func() {
+			defer wg.Done()
+			rotated, _, _ = handler.Rotate(apiKey.ID)
+		}()
+		wg.Wait()
+		_, err := handler.Verify(key)
+		assert.ErrorIs(t, err, utils.ErrAPIKeyInvalid)
+		_, err = handler.Verify(rotated)
+		assert.NoError(t, err)
+		key = rotated
+	}
+	stored, err := handler.Get(apiKey.ID)
+	assert.NoError(t, err)
+	assert.NotZero(t, stored.LastUsedAt)
+	list, _ := handler.ListByOwner("partner-1")
+	assert.Equal(t, stored.LastUsedAt, list[0].LastUsedAt)
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"bytes"
 	"errors"
 	"net/http"
 
@@ -18,64 +19,71 @@ var EchoBinder EchoBinderWithValidation
 type EchoUtil struct{}
 
 // EchoValidator is a struct that implements the echo.Validator interface.
-type EchoValidator struct{}
+type EchoValidator struct {
+	Validator *Validator // The validator of the structs, defaults to DefaultValidator
+}
 
-// Validate is a method that validates the given struct using the ValidateStruct
-// function and returns an error if validation fails.
-func (EchoValidator) Validate(i any) error {
-	return ValidateStruct(i)
+// Validate is a method that validates the given struct using the Validator
+// and returns an error if validation fails.
+func (v EchoValidator) Validate(i any) error {
+	return validatorOrDefault(v.Validator).Struct(i)
 }
 
 // EchoBinderWithValidation is a struct that implements the echo.Binder interface
 // with added validation functionality.
 type EchoBinderWithValidation struct {
 	echo.DefaultBinder
+	Validator *Validator // The validator of the bound structs, defaults to DefaultValidator
 }
 
-// validateWithErrorHandling validates a given struct and error handler
+// validateWithErrorHandling sanitizes and validates a given struct and error handler
 func (b *EchoBinderWithValidation) validateWithErrorHandling(i any, err error) error {
 	if err != nil {
 		return errors.New(err.(*echo.HTTPError).Message.(string))
 	}
-	return ValidateStruct(i)
+	v := validatorOrDefault(b.Validator)
+	if err := v.Sanitize(i); err != nil {
+		return err
+	}
+	return v.Struct(i)
 }
 
-// Bind binds request data, validates it using ValidateStruct(),
+// Bind binds request data, sanitizes and validates it using the Validator,
 // and returns an error if binding or validation fails.
 func (b *EchoBinderWithValidation) Bind(i any, c echo.Context) error {
 	err := b.DefaultBinder.Bind(i, c)
 	return b.validateWithErrorHandling(i, err)
 }
 
-// BindBody binds body data, validates it using ValidateStruct(),
+// BindBody binds body data, sanitizes and validates it using the Validator,
 // and returns an error if binding or validation fails.
 func (b *EchoBinderWithValidation) BindBody(c echo.Context, i any) error {
 	err := b.DefaultBinder.BindBody(c, i)
 	return b.validateWithErrorHandling(i, err)
 }
 
-// BindHeaders binds headers data, validates it using ValidateStruct(),
+// BindHeaders binds headers data, sanitizes and validates it using the Validator,
 // and returns an error if binding or validation fails.
 func (b *EchoBinderWithValidation) BindHeaders(c echo.Context, i any) error {
 	err := b.DefaultBinder.BindHeaders(c, i)
 	return b.validateWithErrorHandling(i, err)
 }
 
-// BindPathParams binds path params, validates them using ValidateStruct(),
+// BindPathParams binds path params, sanitizes and validates them using the Validator,
 // and returns an error if binding or validation fails.
 func (b *EchoBinderWithValidation) BindPathParams(c echo.Context, i any) error {
 	err := b.DefaultBinder.BindPathParams(c, i)
 	return b.validateWithErrorHandling(i, err)
 }
 
-// BindQueryParams binds query params, validates them using ValidateStruct(),
+// BindQueryParams binds query params, sanitizes and validates them using the Validator,
 // and returns an error if binding or validation fails.
 func (b *EchoBinderWithValidation) BindQueryParams(c echo.Context, i any) error {
 	err := b.DefaultBinder.BindQueryParams(c, i)
 	return b.validateWithErrorHandling(i, err)
 }
 
-// BindAll binds all request data, validates it using ValidateStruct(),
+// BindAll binds all request data, sanitizes and validates it using the Validator,
 func (b *EchoBinderWithValidation) BindAll(i any, c echo.Context) error {
 	if err := b.DefaultBinder.BindPathParams(c, i); err != nil {
 		return b.validateWithErrorHandling(i, err)
@@ -87,6 +95,82 @@ func (b *EchoBinderWithValidation) BindAll(i any, c echo.Context) error {
 	return b.validateWithErrorHandling(i, err)
 }
 
+// echoResponseRecorder is an http.ResponseWriter that keeps a copy of the response body,
+// either writing it through to the client or holding it back when buffered is set
+type echoResponseRecorder struct {
+	http.ResponseWriter
+	body     bytes.Buffer
+	buffered bool
+}
+
+// WriteHeader sends the status code unless the response is buffered
+func (r *echoResponseRecorder) WriteHeader(code int) {
+	if !r.buffered {
+		r.ResponseWriter.WriteHeader(code)
+	}
+}
+
+// Write records the data and writes it to the underlying writer unless the response is buffered
+func (r *echoResponseRecorder) Write(b []byte) (int, error) {
+	if r.buffered {
+		return r.body.Write(b)
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying http.ResponseWriter
+func (r *echoResponseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// echoCachedResponse is a serializable snapshot of an HTTP response
+type echoCachedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+}
+
+// recordResponse replaces the response writer of the context with a recorder
+// and returns a function that restores it and returns the recorded response.
+// A buffered response is not sent to the client and must be written with writeTo.
+func recordResponse(c echo.Context, buffered bool) func() echoCachedResponse {
+	res := c.Response()
+	writer := res.Writer
+	recorder := &echoResponseRecorder{ResponseWriter: writer, buffered: buffered}
+	res.Writer = recorder
+	return func() echoCachedResponse {
+		res.Writer = writer
+		header := res.Header().Clone()
+		header.Del(echo.HeaderContentEncoding)
+		header.Del(echo.HeaderContentLength)
+		return echoCachedResponse{
+			StatusCode: res.Status,
+			Header:     header,
+			Body:       recorder.body.Bytes(),
+		}
+	}
+}
+
+// write writes the cached response to the given context
+func (r echoCachedResponse) write(c echo.Context) error {
+	header := c.Response().Header()
+	for k, v := range r.Header {
+		header[k] = v
+	}
+	c.Response().WriteHeader(r.StatusCode)
+	_, err := c.Response().Write(r.Body)
+	return err
+}
+
+// writeTo writes the status code and body of a buffered response
+// to the given writer, using the headers already set on it
+func (r echoCachedResponse) writeTo(w http.ResponseWriter) error {
+	w.WriteHeader(r.StatusCode)
+	_, err := w.Write(r.Body)
+	return err
+}
+
 // DefaultRootHandler handles requests to the root endpoint
 func (EchoUtil) DefaultRootHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, echo.Map{"message": "200 OK"})
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"errors"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// Default values for EchoAPIKeyConfig
+const (
+	DefaultAPIKeyHeader     = "X-API-Key"
+	DefaultAPIKeyContextKey = "api_key"
+)
+
+// ErrAPIKeyMissing is returned when a request has no API key
+var ErrAPIKeyMissing = errors.New("missing api key")
+
+// EchoAPIKeyConfig is the configuration for the APIKeyAuth middleware
+type EchoAPIKeyConfig struct {
+	Handler    APIKeyHandler      // The handler verifying API keys
+	HeaderName string             // The request header holding the API key, defaults to DefaultAPIKeyHeader
+	Skipper    middleware.Skipper // Skipper defines a function to skip the middleware
+}
+
+// APIKeyAuth returns a middleware authenticating requests by the API key header.
+// The verified APIKey is stored in the echo.Context, see GetAPIKey.
+func (EchoUtil) APIKeyAuth(config EchoAPIKeyConfig) echo.MiddlewareFunc {
+	if config.HeaderName == "" {
+		config.HeaderName = DefaultAPIKeyHeader
+	}
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+			key := c.Request().Header.Get(config.HeaderName)
+			if key == "" {
+				return NewCommonErrorUnauthorized(ErrAPIKeyMissing)
+			}
+			apiKey, err := config.Handler.Verify(key)
+			if errors.Is(err, ErrAPIKeyInvalid) || errors.Is(err, ErrAPIKeyExpired) {
+				return NewCommonErrorUnauthorized(err)
+			}
+			if err != nil {
+				return err
+			}
+			c.Set(DefaultAPIKeyContextKey, apiKey)
+			return next(c)
+		}
+	}
+}
+
+// GetAPIKey returns the API key verified by the APIKeyAuth middleware
+func (EchoUtil) GetAPIKey(c echo.Context) (APIKey, bool) {
+	apiKey, ok := c.Get(DefaultAPIKeyContextKey).(APIKey)
+	return apiKey, ok
+}
+
+// AuthAny returns a middleware accepting the request if any of the authentication
+// middlewares accepts it, e.g. JWTAuth or APIKeyAuth. The middlewares are tried in order
+// and the error of the last one is returned if none accepts the request.
+func (EchoUtil) AuthAny(middlewares ...echo.MiddlewareFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var err error
+			for _, m := range middlewares {
+				accepted := false
+				err = m(func(echo.Context) error {
+					accepted = true
+					return nil
+				})(c)
+				if accepted && err == nil {
+					return next(c)
+				}
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package utils_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+func TestEchoUtil_APIKeyAuth(t *testing.T) {
+	handler := newTestAPIKeyHandler(t)
+	key, _, _ := handler.Create("partner-1", nil, 0)
+	echoJWT := utils.EchoJWT.New(testEchoJWTConfig)
+	e := echo.New()
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		resp := utils.ParseErrorResponse(err)
+		_ = c.JSON(resp.StatusCode, resp)
+	}
+	e.GET("/partner", func(c echo.Context) error {
+		apiKey, _ := utils.Echo.GetAPIKey(c)
+		return c.String(http.StatusOK, apiKey.Owner)
+	}, utils.Echo.APIKeyAuth(utils.EchoAPIKeyConfig{Handler: handler}))
+	e.GET("/any", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, utils.Echo.AuthAny(echoJWT.JWTAuth(), utils.Echo.APIKeyAuth(utils.EchoAPIKeyConfig{Handler: handler})))
+	serve := func(path string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("/partner", map[string]string{utils.DefaultAPIKeyHeader: key})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "partner-1", rec.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, serve("/partner", nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve("/partner", map[string]string{utils.DefaultAPIKeyHeader: "invalid"}).Code)
+
+	token := echoJWT.CreateToken(testClaims)
+	assert.Equal(t, http.StatusOK, serve("/any", map[string]string{echo.HeaderAuthorization: "Bearer " + token.SignedString}).Code)
+	assert.Equal(t, http.StatusOK, serve("/any", map[string]string{utils.DefaultAPIKeyHeader: key}).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve("/any", nil).Code)
+}
@@ -0,0 +1,262 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/redis/go-redis/v9"
+)
+
+// Default values for EchoCacheConfig and EchoCacheRouteConfig
+const (
+	DefaultCacheKeyPrefix = "cache"
+	DefaultCacheTTL       = 5 * time.Minute
+)
+
+// HeaderXCache reports whether a response was served from the cache (HIT) or not (MISS)
+const HeaderXCache = "X-Cache"
+
+// Conditional request headers that are not defined by echo
+const (
+	headerETag        = "ETag"
+	headerIfNoneMatch = "If-None-Match"
+)
+
+// Define limit for Redis keys scanned per call when invalidating cache entries.
+const cacheRedisLimitScan = 1000
+
+// EchoCacheConfig is the configuration for EchoCache
+type EchoCacheConfig struct {
+	Client    *RedisClient // Redis client instance used to store cached responses
+	KeyPrefix string       // Prefix for the Redis keys, defaults to DefaultCacheKeyPrefix
+}
+
+// EchoCacheRouteConfig is the route level configuration for the cache middleware
+type EchoCacheRouteConfig struct {
+	TTL          time.Duration                 // How long a response is cached, defaults to DefaultCacheTTL
+	QueryParams  []string                      // Query params that are part of the cache key
+	Headers      []string                      // Request headers that are part of the cache key, e.g. Accept-Language
+	Tags         []string                      // Tags attached to cached responses, used for invalidation
+	TagsFunc     func(c echo.Context) []string // Optional function returning additional tags per request
+	CacheControl string                        // Cache-Control header value, defaults to "public, max-age=<TTL>", or "private, max-age=<TTL>" if Private
+	Private      bool                          // Whether to cache requests with Authorization or Cookie headers, keyed by their values
+	Skipper      middleware.Skipper            // Skipper defines a function to skip the middleware
+}
+
+// EchoCache caches GET responses in Redis and invalidates them by tag or key prefix
+type EchoCache struct {
+	client    *RedisClient
+	keyPrefix string
+}
+
+// NewCache creates a new response cache using the provided configuration
+func (EchoUtil) NewCache(config EchoCacheConfig) *EchoCache {
+	if config.KeyPrefix = strings.TrimSpace(config.KeyPrefix); config.KeyPrefix == "" {
+		config.KeyPrefix = DefaultCacheKeyPrefix
+	}
+	return &EchoCache{
+		client:    config.Client,
+		keyPrefix: config.KeyPrefix,
+	}
+}
+
+// Middleware returns a middleware that serves GET responses from the cache.
+// Only 200 OK responses without Set-Cookie are stored. Responses carry an ETag,
+// and requests with a matching If-None-Match get 304 Not Modified. Requests with
+// Authorization or Cookie headers are not cached unless the route is Private.
+// Redis errors are not fatal, the request is then handled without the cache.
+func (ec *EchoCache) Middleware(config EchoCacheRouteConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultCacheTTL
+	}
+	if config.CacheControl == "" {
+		visibility := "public"
+		if config.Private {
+			visibility = "private"
+		}
+		config.CacheControl = fmt.Sprintf("%s, max-age=%d", visibility, int64(config.TTL.Seconds()))
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if config.Skipper(c) || req.Method != http.MethodGet {
+				return next(c)
+			}
+			if !config.Private && (req.Header.Get(echo.HeaderAuthorization) != "" || req.Header.Get(echo.HeaderCookie) != "") {
+				return next(c)
+			}
+			key := ec.key(c, config)
+			header := c.Response().Header()
+			cached := echoCachedResponse{}
+			if err := ec.client.GetStruct(key, &cached); err == nil {
+				header.Set(HeaderXCache, "HIT")
+				header.Set(echo.HeaderCacheControl, config.CacheControl)
+				if matchETag(c.Request().Header.Get(headerIfNoneMatch), cached.Header.Get(headerETag)) {
+					header.Set(headerETag, cached.Header.Get(headerETag))
+					return c.NoContent(http.StatusNotModified)
+				}
+				return cached.write(c)
+			}
+			header.Set(HeaderXCache, "MISS")
+			stopRecording := recordResponse(c, true)
+			err := next(c)
+			response := stopRecording()
+			if err != nil {
+				return err
+			}
+			if response.StatusCode == http.StatusOK && header.Get(echo.HeaderSetCookie) == "" {
+				etag := fmt.Sprintf(`"%s"`, String.SHA256(string(response.Body)))
+				header.Set(headerETag, etag)
+				header.Set(echo.HeaderCacheControl, config.CacheControl)
+				response.Header.Set(headerETag, etag)
+				response.Header.Del(HeaderXCache)
+				tags := config.Tags
+				if config.TagsFunc != nil {
+					tags = append(append([]string{}, tags...), config.TagsFunc(c)...)
+				}
+				_ = ec.store(key, response, config.TTL, tags)
+				if matchETag(c.Request().Header.Get(headerIfNoneMatch), etag) {
+					response = echoCachedResponse{StatusCode: http.StatusNotModified}
+				}
+			}
+			return response.writeTo(c.Response().Writer)
+		}
+	}
+}
+
+// InvalidateTags deletes all cached responses attached to any of the given tags.
+func (ec *EchoCache) InvalidateTags(tags ...string) error {
+	ctx := context.TODO()
+	for _, tag := range tags {
+		tagKey := ec.tagKey(tag)
+		keys, err := ec.client.SMembers(ctx, tagKey).Result()
+		if err != nil {
+			return err
+		}
+		if err := ec.client.Del(ctx, append(keys, tagKey)...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InvalidatePrefix deletes all cached responses of the given method and path and the paths
+// below it. Keys have the form "METHOD:path:hash" and the prefix is matched on path segment
+// boundaries, e.g. InvalidatePrefix("GET:/users") removes every cached variant of /users and
+// /users/1, but not of /users-admin. Glob characters in the prefix are matched literally.
+func (ec *EchoCache) InvalidatePrefix(prefix string) error {
+	prefix = escapeRedisPattern(ec.keyPrefix + ":" + strings.TrimSuffix(prefix, "/"))
+	for _, pattern := range []string{prefix + ":*", prefix + "/*"} {
+		if err := ec.deleteMatching(pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteMatching deletes all keys matching the glob pattern
+func (ec *EchoCache) deleteMatching(pattern string) error {
+	var cursor uint64
+	ctx := context.TODO()
+	for {
+		var err error
+		var keys []string
+		keys, cursor, err = ec.client.Scan(ctx, cursor, pattern, cacheRedisLimitScan).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := ec.client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
+		if cursor == 0 {
+			break
+		}
+	}
+	return nil
+}
+
+// store saves the response in Redis and adds its key to the given tags
+func (ec *EchoCache) store(key string, response echoCachedResponse, ttl time.Duration, tags []string) error {
+	if err := ec.client.SetStruct(key, response, ttl); err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	ctx := context.TODO()
+	pipe := ec.client.Pipeline()
+	ttlCmds := make([]*redis.DurationCmd, len(tags))
+	for i, tag := range tags {
+		tagKey := ec.tagKey(tag)
+		pipe.SAdd(ctx, tagKey, key)
+		ttlCmds[i] = pipe.TTL(ctx, tagKey)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	// tag sets live as long as their longest cached response
+	for i, tag := range tags {
+		if ttlCmds[i].Val() < ttl {
+			pipe.Expire(ctx, ec.tagKey(tag), ttl)
+		}
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// key returns the Redis key for the request, derived from the method, the path,
+// the configured query params and headers, and the credentials of Private routes
+func (ec *EchoCache) key(c echo.Context, config EchoCacheRouteConfig) string {
+	req := c.Request()
+	query := req.URL.Query()
+	var variant strings.Builder
+	for _, name := range config.QueryParams {
+		variant.WriteString("q:" + name + "=" + strings.Join(query[name], ",") + "\n")
+	}
+	for _, name := range config.Headers {
+		variant.WriteString("h:" + name + "=" + strings.Join(req.Header.Values(name), ",") + "\n")
+	}
+	if config.Private {
+		for _, name := range []string{echo.HeaderAuthorization, echo.HeaderCookie} {
+			variant.WriteString("p:" + name + "=" + strings.Join(req.Header.Values(name), ",") + "\n")
+		}
+	}
+	var builder strings.Builder
+	builder.WriteString(ec.keyPrefix)
+	builder.WriteByte(':')
+	builder.WriteString(req.Method)
+	builder.WriteByte(':')
+	builder.WriteString(req.URL.Path)
+	builder.WriteByte(':')
+	builder.WriteString(String.SHA256(variant.String()))
+	return builder.String()
+}
+
+// tagKey returns the Redis key of the set holding the cache keys of a tag
+func (ec *EchoCache) tagKey(tag string) string {
+	return ec.keyPrefix + ":tag:" + tag
+}
+
+// matchETag reports whether the If-None-Match header value matches the given ETag
+func matchETag(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+	for _, v := range strings.Split(ifNoneMatch, ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == "*" || v == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package utils_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+func newCacheTestServer(t *testing.T) (*echo.Echo, *utils.EchoCache, *int) {
+	_, client := newTestRedisClient(t)
+	calls := 0
+	cache := utils.Echo.NewCache(utils.EchoCacheConfig{Client: client})
+	e := utils.Echo.New()
+	e.GET("/products/:id", func(c echo.Context) error {
+		calls++
+		return c.JSON(http.StatusOK, echo.Map{
+			"id":    c.Param("id"),
+			"lang":  c.Request().Header.Get("Accept-Language"),
+			"calls": calls,
+		})
+	}, cache.Middleware(utils.EchoCacheRouteConfig{
+		TTL:         time.Minute,
+		QueryParams: []string{"currency"},
+		Headers:     []string{"Accept-Language"},
+		Tags:        []string{"products"},
+		TagsFunc: func(c echo.Context) []string {
+			return []string{"product:" + c.Param("id")}
+		},
+	}))
+	e.GET("/me", func(c echo.Context) error {
+		calls++
+		return c.JSON(http.StatusOK, echo.Map{"user": c.Request().Header.Get(echo.HeaderAuthorization)})
+	}, cache.Middleware(utils.EchoCacheRouteConfig{TTL: time.Minute, Private: true}))
+	e.GET("/missing", func(c echo.Context) error {
+		calls++
+		return c.NoContent(http.StatusNotFound)
+	}, cache.Middleware(utils.EchoCacheRouteConfig{}))
+	return e, cache, &calls
+}
+
+func serveCacheRequest(e *echo.Echo, path string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestEchoCache_Middleware(t *testing.T) {
+	t.Run("hit and miss", func(t *testing.T) {
+		e, _, calls := newCacheTestServer(t)
+		first := serveCacheRequest(e, "/products/1?currency=THB&page=1", nil)
+		second := serveCacheRequest(e, "/products/1?currency=THB&page=2", nil)
+		assert.Equal(t, 1, *calls)
+		assert.Equal(t, http.StatusOK, second.Code)
+		assert.Equal(t, "MISS", first.Header().Get(utils.HeaderXCache))
+		assert.Equal(t, "HIT", second.Header().Get(utils.HeaderXCache))
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, first.Header().Get("ETag"), second.Header().Get("ETag"))
+		assert.Equal(t, echo.MIMEApplicationJSON, second.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, "public, max-age=60", second.Header().Get(echo.HeaderCacheControl))
+	})
+
+	t.Run("key variants", func(t *testing.T) {
+		e, _, calls := newCacheTestServer(t)
+		serveCacheRequest(e, "/products/1?currency=THB", nil)
+		serveCacheRequest(e, "/products/1?currency=USD", nil)
+		serveCacheRequest(e, "/products/1?currency=THB", map[string]string{"Accept-Language": "th"})
+		serveCacheRequest(e, "/products/2?currency=THB", nil)
+		assert.Equal(t, 4, *calls)
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		e, _, _ := newCacheTestServer(t)
+		first := serveCacheRequest(e, "/products/1", nil)
+		etag := first.Header().Get("ETag")
+		assert.NotEmpty(t, etag)
+		rec := serveCacheRequest(e, "/products/1", map[string]string{"If-None-Match": etag})
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Empty(t, rec.Body.String())
+		assert.Equal(t, etag, rec.Header().Get("ETag"))
+	})
+
+	t.Run("credentials are not cached", func(t *testing.T) {
+		e, _, calls := newCacheTestServer(t)
+		serveCacheRequest(e, "/products/1", map[string]string{echo.HeaderAuthorization: "Bearer alice"})
+		rec := serveCacheRequest(e, "/products/1", map[string]string{echo.HeaderAuthorization: "Bearer bob"})
+		serveCacheRequest(e, "/products/1", map[string]string{echo.HeaderCookie: "session=alice"})
+		assert.Equal(t, 3, *calls)
+		assert.Empty(t, rec.Header().Get(utils.HeaderXCache))
+	})
+
+	t.Run("private", func(t *testing.T) {
+		e, _, calls := newCacheTestServer(t)
+		serveCacheRequest(e, "/me", map[string]string{echo.HeaderAuthorization: "Bearer alice"})
+		alice := serveCacheRequest(e, "/me", map[string]string{echo.HeaderAuthorization: "Bearer alice"})
+		bob := serveCacheRequest(e, "/me", map[string]string{echo.HeaderAuthorization: "Bearer bob"})
+		assert.Equal(t, 2, *calls)
+		assert.Equal(t, "HIT", alice.Header().Get(utils.HeaderXCache))
+		assert.Equal(t, "private, max-age=60", alice.Header().Get(echo.HeaderCacheControl))
+		assert.JSONEq(t, `{"user":"Bearer bob"}`, bob.Body.String())
+	})
+
+	t.Run("non 200 responses are not cached", func(t *testing.T) {
+		e, _, calls := newCacheTestServer(t)
+		serveCacheRequest(e, "/missing", nil)
+		rec := serveCacheRequest(e, "/missing", nil)
+		assert.Equal(t, 2, *calls)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestEchoCache_Invalidate(t *testing.T) {
+	t.Run("by tag", func(t *testing.T) {
+		e, cache, calls := newCacheTestServer(t)
+		serveCacheRequest(e, "/products/1", nil)
+		serveCacheRequest(e, "/products/2", nil)
+		assert.NoError(t, cache.InvalidateTags("product:1"))
+		serveCacheRequest(e, "/products/1", nil)
+		serveCacheRequest(e, "/products/2", nil)
+		assert.Equal(t, 3, *calls)
+		assert.NoError(t, cache.InvalidateTags("products"))
+		serveCacheRequest(e, "/products/1", nil)
+		serveCacheRequest(e, "/products/2", nil)
+		assert.Equal(t, 5, *calls)
+	})
+
+	t.Run("by prefix", func(t *testing.T) {
+		e, cache, calls := newCacheTestServer(t)
+		serveCacheRequest(e, "/products/1", nil)
+		serveCacheRequest(e, "/products/2", nil)
+		assert.NoError(t, cache.InvalidatePrefix("GET:/products/1"))
+		serveCacheRequest(e, "/products/1", nil)
+		serveCacheRequest(e, "/products/2", nil)
+		assert.Equal(t, 3, *calls)
+		assert.NoError(t, cache.InvalidatePrefix("GET:/products"))
+		serveCacheRequest(e, "/products/1", nil)
+		serveCacheRequest(e, "/products/2", nil)
+		assert.Equal(t, 5, *calls)
+	})
+
+	t.Run("by prefix on path segments", func(t *testing.T) {
+		e, cache, calls := newCacheTestServer(t)
+		serveCacheRequest(e, "/products/1", nil)
+		serveCacheRequest(e, "/products/10", nil)
+		assert.NoError(t, cache.InvalidatePrefix("GET:/products/*"))
+		assert.NoError(t, cache.InvalidatePrefix("GET:/products/?"))
+		assert.NoError(t, cache.InvalidatePrefix("GET:/products/1"))
+		serveCacheRequest(e, "/products/1", nil)
+		serveCacheRequest(e, "/products/10", nil)
+		assert.Equal(t, 3, *calls)
+		assert.NoError(t, cache.InvalidatePrefix("GET:/products/"))
+		serveCacheRequest(e, "/products/10", nil)
+		assert.Equal(t, 4, *calls)
+	})
+}
+
+func TestEchoCache_TagTTL(t *testing.T) {
+	s, client := newTestRedisClient(t)
+	cache := utils.Echo.NewCache(utils.EchoCacheConfig{Client: client})
+	e := utils.Echo.New()
+	e.GET("/products/:id", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, echo.Map{"id": c.Param("id")})
+	}, cache.Middleware(utils.EchoCacheRouteConfig{TTL: time.Minute, Tags: []string{"products"}}))
+
+	serveCacheRequest(e, "/products/1", nil)
+	assert.Equal(t, time.Minute, s.TTL("cache:tag:products"))
+	s.FastForward(30 * time.Second)
+	serveCacheRequest(e, "/products/2", nil)
+	assert.Equal(t, time.Minute, s.TTL("cache:tag:products"))
+}
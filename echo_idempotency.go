@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/exp/slices"
+)
+
+// Default values for EchoIdempotencyConfig
+const (
+	DefaultIdempotencyHeader    = "Idempotency-Key"
+	DefaultIdempotencyKeyPrefix = "idempotency"
+	DefaultIdempotencyTTL       = 24 * time.Hour
+	DefaultIdempotencyLockTTL   = time.Minute
+)
+
+// HeaderIdempotentReplayed is set on responses replayed from a previous execution
+const HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+// Idempotency error code constants
+const (
+	ErrCodeIdempotencyKeyInUse    = "IDEMPOTENCY_KEY_IN_USE"
+	ErrCodeIdempotencyKeyMismatch = "IDEMPOTENCY_KEY_MISMATCH"
+)
+
+var (
+	ErrIdempotencyKeyInUse    = errors.New("a request with the same idempotency key is in progress")    // Error for concurrent duplicate requests.
+	ErrIdempotencyKeyMismatch = errors.New("idempotency key was already used with a different payload") // Error for reused keys with a different body.
+)
+
+// EchoIdempotencyConfig is the configuration for the idempotency middleware
+type EchoIdempotencyConfig struct {
+	Client        *RedisClient                // Redis client instance used to store idempotency records
+	KeyPrefix     string                      // Prefix for the Redis keys, defaults to DefaultIdempotencyKeyPrefix
+	HeaderName    string                      // Request header holding the key, defaults to DefaultIdempotencyHeader
+	Methods       []string                    // Request methods the middleware applies to, defaults to POST and PATCH
+	TTL           time.Duration               // How long a completed response is replayed, defaults to DefaultIdempotencyTTL
+	LockTTL       time.Duration               // How long an in-flight request holds the key without being extended, defaults to DefaultIdempotencyLockTTL
+	Skipper       middleware.Skipper          // Skipper defines a function to skip the middleware
+	PrincipalFunc func(c echo.Context) string // Optional function returning the principal keys are scoped to, e.g. the user ID
+}
+
+func init() {
+	RegisterError(ErrorDefinition{
+		Code:       ErrCodeIdempotencyKeyInUse,
+		StatusCode: http.StatusConflict,
+		Message:    ErrIdempotencyKeyInUse.Error(),
+	})
+	RegisterError(ErrorDefinition{
+		Code:       ErrCodeIdempotencyKeyMismatch,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    ErrIdempotencyKeyMismatch.Error(),
+	})
+}
+
+// idempotencyRecord is the state of an idempotency key stored in Redis
+type idempotencyRecord struct {
+	RequestHash string              `json:"requestHash"`        // SHA256 hash of the request URI and body
+	Response    *echoCachedResponse `json:"response,omitempty"` // Response of the first execution, nil while in flight
+}
+
+// Idempotency returns a middleware that executes a request only once per idempotency key,
+// scoped to the method, the route and the principal of the request.
+// The first request claims the key and its response is stored; duplicates replay the
+// stored response, concurrent duplicates get 409 Conflict and duplicates with a different
+// URI or body get 422 Unprocessable Entity. Handler errors and 5xx responses release the key.
+// The claim is extended every half LockTTL while the handler runs, so it only expires
+// if the process stops before the response is stored.
+func (EchoUtil) Idempotency(config EchoIdempotencyConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+	if config.KeyPrefix = strings.TrimSpace(config.KeyPrefix); config.KeyPrefix == "" {
+		config.KeyPrefix = DefaultIdempotencyKeyPrefix
+	}
+	if config.HeaderName == "" {
+		config.HeaderName = DefaultIdempotencyHeader
+	}
+	if len(config.Methods) == 0 {
+		config.Methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultIdempotencyTTL
+	}
+	if config.LockTTL <= 0 {
+		config.LockTTL = DefaultIdempotencyLockTTL
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if config.Skipper(c) || !slices.Contains(config.Methods, req.Method) {
+				return next(c)
+			}
+			idempotencyKey := strings.TrimSpace(req.Header.Get(config.HeaderName))
+			if idempotencyKey == "" {
+				return next(c)
+			}
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return err
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			principal := ""
+			if config.PrincipalFunc != nil {
+				principal = config.PrincipalFunc(c)
+			}
+			key := config.KeyPrefix + ":" + String.SHA256(strings.Join([]string{req.Method, c.Path(), principal, idempotencyKey}, "\n"))
+			record := idempotencyRecord{
+				RequestHash: String.SHA256(req.URL.RequestURI() + "\n" + string(body)),
+			}
+			ok, err := config.Client.SetNXStruct(key, record, config.LockTTL)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return replayIdempotentResponse(c, config.Client, key, record.RequestHash)
+			}
+			releaseLock := holdIdempotencyLock(config.Client, key, config.LockTTL)
+			stopRecording := recordResponse(c, false)
+			err = next(c)
+			response := stopRecording()
+			releaseLock()
+			if err != nil || response.StatusCode >= http.StatusInternalServerError {
+				_ = config.Client.Del(context.TODO(), key).Err()
+				return err
+			}
+			record.Response = &response
+			return config.Client.SetStruct(key, record, config.TTL)
+		}
+	}
+}
+
+// holdIdempotencyLock extends the claim of an in-flight request every half lockTTL,
+// returning a function that stops extending it once the handler returned
+func holdIdempotencyLock(client *RedisClient, key string, lockTTL time.Duration) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(lockTTL / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				_ = client.Expire(context.TODO(), key, lockTTL).Err()
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// replayIdempotentResponse writes the stored response of the given key,
+// or returns an error if the request is still in flight or its payload differs
+func replayIdempotentResponse(c echo.Context, client *RedisClient, key, requestHash string) error {
+	record := idempotencyRecord{}
+	if err := client.GetStruct(key, &record); err != nil && err != redis.Nil {
+		return err
+	}
+	if record.RequestHash != "" && record.RequestHash != requestHash {
+		return NewCommonError(ErrCodeIdempotencyKeyMismatch, ErrIdempotencyKeyMismatch)
+	}
+	if record.Response == nil {
+		return NewCommonError(ErrCodeIdempotencyKeyInUse, ErrIdempotencyKeyInUse)
+	}
+	c.Response().Header().Set(HeaderIdempotentReplayed, "true")
+	return record.Response.write(c)
+}
@@ -0,0 +1,173 @@
+package utils_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+func newIdempotencyTestServer(t *testing.T) (*echo.Echo, *int) {
+	_, client := newTestRedisClient(t)
+	calls := 0
+	e := echo.New()
+	e.Use(utils.Echo.Idempotency(utils.EchoIdempotencyConfig{Client: client}))
+	e.POST("/charges", func(c echo.Context) error {
+		calls++
+		c.Response().Header().Set("X-Charge-Count", "1")
+		return c.JSON(http.StatusCreated, echo.Map{"calls": calls})
+	})
+	e.POST("/failed", func(c echo.Context) error {
+		calls++
+		return echo.ErrInternalServerError
+	})
+	return e, &calls
+}
+
+func serveIdempotencyRequest(e *echo.Echo, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(utils.DefaultIdempotencyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestEchoIdempotency(t *testing.T) {
+	t.Run("replay duplicate request", func(t *testing.T) {
+		e, calls := newIdempotencyTestServer(t)
+		first := serveIdempotencyRequest(e, "/charges", "key-1", `{"amount":100}`)
+		second := serveIdempotencyRequest(e, "/charges", "key-1", `{"amount":100}`)
+		assert.Equal(t, 1, *calls)
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "1", second.Header().Get("X-Charge-Count"))
+		assert.Equal(t, "true", second.Header().Get(utils.HeaderIdempotentReplayed))
+		assert.Empty(t, first.Header().Get(utils.HeaderIdempotentReplayed))
+	})
+
+	t.Run("different payload", func(t *testing.T) {
+		e, calls := newIdempotencyTestServer(t)
+		serveIdempotencyRequest(e, "/charges", "key-1", `{"amount":100}`)
+		rec := serveIdempotencyRequest(e, "/charges", "key-1", `{"amount":200}`)
+		assert.Equal(t, 1, *calls)
+		assert.NotEqual(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("without key", func(t *testing.T) {
+		e, calls := newIdempotencyTestServer(t)
+		serveIdempotencyRequest(e, "/charges", "", `{"amount":100}`)
+		serveIdempotencyRequest(e, "/charges", "", `{"amount":100}`)
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("scoped to route", func(t *testing.T) {
+		e, calls := newIdempotencyTestServer(t)
+		serveIdempotencyRequest(e, "/charges", "key-1", `{}`)
+		rec := serveIdempotencyRequest(e, "/failed", "key-1", `{}`)
+		assert.Equal(t, 2, *calls)
+		assert.Empty(t, rec.Header().Get(utils.HeaderIdempotentReplayed))
+	})
+
+	t.Run("handler error releases key", func(t *testing.T) {
+		e, calls := newIdempotencyTestServer(t)
+		serveIdempotencyRequest(e, "/failed", "key-1", `{}`)
+		rec := serveIdempotencyRequest(e, "/failed", "key-1", `{}`)
+		assert.Equal(t, 2, *calls)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestEchoIdempotency_Errors(t *testing.T) {
+	utils.SetErrorStackEnabled(true)
+	defer utils.SetErrorStackEnabled(false)
+	_, client := newTestRedisClient(t)
+	var handlerErr error
+	e := echo.New()
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		handlerErr = err
+	}
+	e.Use(utils.Echo.Idempotency(utils.EchoIdempotencyConfig{Client: client}))
+	e.POST("/", func(c echo.Context) error {
+		rec := serveIdempotencyRequest(e, "/", "key-1", `{"amount":100}`)
+		assert.Empty(t, rec.Body.String())
+		inFlight := utils.ParseCommonError(handlerErr)
+		assert.Equal(t, http.StatusConflict, inFlight.StatusCode)
+		assert.Equal(t, utils.ErrCodeIdempotencyKeyInUse, inFlight.ErrorCode)
+		return c.NoContent(http.StatusOK)
+	})
+
+	serveIdempotencyRequest(e, "/", "key-1", `{"amount":100}`)
+	serveIdempotencyRequest(e, "/", "key-1", `{"amount":200}`)
+	mismatch := utils.ParseCommonError(handlerErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, mismatch.StatusCode)
+	assert.Equal(t, utils.ErrCodeIdempotencyKeyMismatch, mismatch.ErrorCode)
+	assert.Equal(t, utils.ErrIdempotencyKeyMismatch, mismatch.ErrorInstance)
+	assert.NotEmpty(t, mismatch.StackTrace())
+}
+
+func TestEchoIdempotency_Principal(t *testing.T) {
+	_, client := newTestRedisClient(t)
+	calls := 0
+	e := echo.New()
+	e.Use(utils.Echo.Idempotency(utils.EchoIdempotencyConfig{
+		Client: client,
+		PrincipalFunc: func(c echo.Context) string {
+			return c.Request().Header.Get("X-User")
+		},
+	}))
+	e.POST("/charges", func(c echo.Context) error {
+		calls++
+		return c.JSON(http.StatusCreated, echo.Map{"user": c.Request().Header.Get("X-User")})
+	})
+	serve := func(user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/charges", strings.NewReader(`{}`))
+		req.Header.Set(utils.DefaultIdempotencyHeader, "key-1")
+		req.Header.Set("X-User", user)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	serve("alice")
+	rec := serve("bob")
+	assert.Equal(t, 2, calls)
+	assert.JSONEq(t, `{"user":"bob"}`, rec.Body.String())
+	rec = serve("alice")
+	assert.Equal(t, 2, calls)
+	assert.JSONEq(t, `{"user":"alice"}`, rec.Body.String())
+}
+
+func TestEchoIdempotency_LockExtended(t *testing.T) {
+	s, client := newTestRedisClient(t)
+	var handlerErr error
+	e := echo.New()
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		handlerErr = err
+	}
+	e.Use(utils.Echo.Idempotency(utils.EchoIdempotencyConfig{Client: client, LockTTL: 100 * time.Millisecond}))
+	calls := 0
+	e.POST("/", func(c echo.Context) error {
+		if calls++; calls > 1 {
+			return c.NoContent(http.StatusOK)
+		}
+		for i := 0; i < 4; i++ {
+			time.Sleep(60 * time.Millisecond)
+			s.FastForward(60 * time.Millisecond)
+		}
+		serveIdempotencyRequest(e, "/", "key-1", `{}`)
+		assert.Equal(t, http.StatusConflict, utils.ParseCommonError(handlerErr).StatusCode)
+		return c.NoContent(http.StatusOK)
+	})
+	rec := serveIdempotencyRequest(e, "/", "key-1", `{}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, calls)
+}
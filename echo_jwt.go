@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -16,8 +17,11 @@ var EchoJWT EchoJWTUtil
 // EchoJWTUtil is a utility struct that provides methods
 // for working with JWT tokens in the context of the Echo web framework
 type EchoJWTUtil struct {
-	Config        *EchoJWTConfig // The configuration for EchoJWTUtil
-	echoJWTConfig echojwt.Config // The configuration for the echojwt library
+	Config        *EchoJWTConfig    // The configuration for EchoJWTUtil
+	echoJWTConfig echojwt.Config    // The configuration for the echojwt library
+	keySet        *jwtKeySet        // The keys used to sign and verify tokens
+	configErr     error             // The error of the configured keys, returned when signing and verifying
+	newClaims     func() jwt.Claims // The constructor of custom claims, nil for jwt.RegisteredClaims
 }
 
 // JWTToken is a helper struct for returning signed JWT tokens
@@ -28,61 +32,180 @@ type JWTToken struct {
 
 // EchoJWTConfig is the configuration struct for EchoJWTUtil
 type EchoJWTConfig struct {
-	SigningKey        string                                       // The signing key used to sign JWT tokens
+	SigningKey        string                                       // The HS256 signing key used to sign JWT tokens without a kid header
+	Keys              []JWTKey                                     // Additional keys identified by the kid header, e.g. RS256, ES256 or EdDSA keys
+	SigningKeyID      string                                       // The ID of the key used to sign new tokens, defaults to the first key with a private key
 	ExpiresTTL        time.Duration                                // The duration until which the token should be valid
+	Issuer            string                                       // The issuer set on new tokens, also accepted when parsing
+	Audience          []string                                     // The audience set on new tokens, also accepted when parsing
+	ExpectedIssuers   []string                                     // Additional issuers accepted when parsing, any issuer if empty
+	ExpectedAudiences []string                                     // Additional audiences accepted when parsing, any audience if empty
+	ClockSkew         time.Duration                                // The allowed clock skew when checking exp, nbf and iat
+	MaxTokenAge       time.Duration                                // The maximum age of a token since iat, no limit if zero
+	TokenLookup       string                                       // The sources of the token in the echo-jwt format, e.g. "header:Authorization:Bearer ,cookie:token,query:token"
+	Cookie            JWTCookieConfig                              // The configuration of the token cookie
+	Encryption        *JWTEncryptionConfig                         // Optional encryption of signed tokens as JWE compact tokens
 	BeforeSuccessFunc func(token *jwt.Token, c echo.Context) error // A callback function to execute before a successful authentication
+	RevocationHandler JWTRevocationHandler                         // Optional store of revoked tokens checked by the middleware
+	SessionHandler    SessionHandler                               // Optional session store, tokens are only valid while their session exists
 }
 
-// New creates and returns a new instance of EchoJWTUtil
+// New creates and returns a new instance of EchoJWTUtil.
+// An invalid configured key or encryption is returned as an error when signing and verifying tokens.
 func (EchoJWTUtil) New(config *EchoJWTConfig) *EchoJWTUtil {
+	echoJWTUtil, _ := newEchoJWTUtil(config, nil)
+	return echoJWTUtil
+}
+
+// NewWithKeys creates and returns a new instance of EchoJWTUtil,
+// returning an error if a configured key or the encryption is invalid
+func (EchoJWTUtil) NewWithKeys(config *EchoJWTConfig) (*EchoJWTUtil, error) {
+	echoJWTUtil, err := newEchoJWTUtil(config, nil)
+	if err != nil {
+		return nil, err
+	}
+	return echoJWTUtil, nil
+}
+
+// newEchoJWTUtil creates a new instance of EchoJWTUtil using the given claims constructor,
+// returning it together with the error of the configured keys and encryption
+func newEchoJWTUtil(config *EchoJWTConfig, newClaims func() jwt.Claims) (*EchoJWTUtil, error) {
+	keySet, err := newJWTKeySet(config)
+	if err == nil && config.Encryption != nil {
+		err = config.Encryption.validate()
+	}
 	echoJWTUtil := &EchoJWTUtil{
 		Config: config,
 		echoJWTConfig: echojwt.Config{
-			SigningKey: []byte(config.SigningKey),
+			SigningKey:  []byte(config.SigningKey),
+			TokenLookup: config.TokenLookup,
 		},
+		keySet:    keySet,
+		configErr: err,
+		newClaims: newClaims,
 	}
 	echoJWTUtil.echoJWTConfig.ParseTokenFunc = echoJWTUtil.ParseTokenFunc
-	return echoJWTUtil
+	return echoJWTUtil, err
 }
 
-// CreateToken creates and returns a new JWTToken
+// CreateToken creates and returns a new JWTToken signed with the current signing key.
+// The SignedString is empty if the token cannot be signed, see CreateTokenWithError.
 func (eJWT EchoJWTUtil) CreateToken(claims jwt.RegisteredClaims) JWTToken {
+	token, _ := eJWT.CreateTokenWithError(claims)
+	return token
+}
+
+// CreateTokenWithError creates and returns a new JWTToken signed with the current signing key,
+// returning an error if there is no signing key or the token cannot be signed or encrypted
+func (eJWT EchoJWTUtil) CreateTokenWithError(claims jwt.RegisteredClaims) (JWTToken, error) {
+	eJWT.setDefaultClaims(&claims)
+	signedToken, err := eJWT.signToken(claims)
+	return JWTToken{
+		SignedString: signedToken,
+		Claims:       claims,
+	}, err
+}
+
+// setDefaultClaims sets the ID, Issuer, Audience, IssuedAt and ExpiresAt claims when they are empty
+func (eJWT EchoJWTUtil) setDefaultClaims(claims *jwt.RegisteredClaims) {
 	if claims.ID == "" {
 		claims.ID = String.UUID()
 	}
+	if claims.Issuer == "" {
+		claims.Issuer = eJWT.Config.Issuer
+	}
+	if len(claims.Audience) == 0 && len(eJWT.Config.Audience) > 0 {
+		claims.Audience = eJWT.Config.Audience
+	}
 	if claims.IssuedAt == nil {
 		claims.IssuedAt = jwt.NewNumericDate(time.Now())
 	}
 	if claims.ExpiresAt == nil {
 		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(eJWT.Config.ExpiresTTL))
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, _ := token.SignedString([]byte(eJWT.Config.SigningKey))
-	return JWTToken{
-		SignedString: signedToken,
-		Claims:       claims,
+}
+
+// signToken signs the claims with the current signing key,
+// setting the kid header when the key has an ID,
+// and encrypts the signed token when encryption is configured
+func (eJWT EchoJWTUtil) signToken(claims jwt.Claims) (string, error) {
+	keySet, err := eJWT.getKeySet()
+	if err != nil {
+		return "", err
+	}
+	key, err := keySet.signingKey()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.Method, claims)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
+	signedToken, err := token.SignedString(key.PrivateKey)
+	if err != nil || eJWT.Config.Encryption == nil {
+		return signedToken, err
 	}
+	return eJWT.Config.Encryption.encryptToken(signedToken)
 }
 
-// KeyFunc is a helper function used by ParseToken
-// to extract the signing key from the EchoJWTConfig object
+// KeyFunc is a helper function used by ParseToken to look up the verification key
+// by the kid header and check that the token uses the signing method of the key
 func (eJWT EchoJWTUtil) KeyFunc(token *jwt.Token) (any, error) {
-	if token.Method.Alg() != jwt.SigningMethodHS256.Name {
+	keySet, err := eJWT.getKeySet()
+	if err != nil {
+		return nil, err
+	}
+	kid, _ := token.Header["kid"].(string)
+	key, ok := keySet.get(kid)
+	if !ok {
+		return nil, fmt.Errorf("unexpected jwt key id=%v", token.Header["kid"])
+	}
+	if token.Method.Alg() != key.Method.Alg() {
 		return nil, fmt.Errorf("unexpected jwt signing method=%v", token.Header["alg"])
 	}
-	return []byte(eJWT.Config.SigningKey), nil
+	return key.verifyKey(), nil
 }
 
 // ParseToken is a helper function used to parse
-// and validate JWT tokens using the echo-jwt library
+// and validate JWT tokens using the echo-jwt library.
+// Encrypted tokens are decrypted first when encryption is configured.
+// The registered claims are validated against the configured issuers,
+// audiences, clock skew and maximum token age.
+// Custom claims are also validated using ValidateStruct.
 func (eJWT EchoJWTUtil) ParseToken(signedToken string) (*jwt.Token, error) {
-	token, err := jwt.ParseWithClaims(signedToken, &jwt.RegisteredClaims{}, eJWT.KeyFunc)
+	if eJWT.Config.Encryption != nil {
+		var err error
+		if signedToken, err = eJWT.Config.Encryption.decryptToken(signedToken); err != nil {
+			return nil, err
+		}
+	}
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+	token, err := parser.ParseWithClaims(signedToken, eJWT.claims(), eJWT.KeyFunc)
 	if err != nil {
 		return nil, err
 	}
+	if err := eJWT.validateClaims(token.Claims); err != nil {
+		return nil, err
+	}
+	if eJWT.newClaims != nil {
+		if err := ValidateStruct(token.Claims); err != nil {
+			return nil, err
+		}
+	}
 	return token, nil
 }
 
+// claims returns new empty claims to parse a token into
+func (eJWT EchoJWTUtil) claims() jwt.Claims {
+	if eJWT.newClaims != nil {
+		return eJWT.newClaims()
+	}
+	if eJWT.Config.SessionHandler != nil {
+		return &JWTSessionClaims{}
+	}
+	return &jwt.RegisteredClaims{}
+}
+
 // ParseTokenFunc is a callback function used to parse
 // and validate JWT tokens within the context of the echo-jwt middleware
 func (eJWT EchoJWTUtil) ParseTokenFunc(c echo.Context, auth string) (any, error) {
@@ -90,6 +213,12 @@ func (eJWT EchoJWTUtil) ParseTokenFunc(c echo.Context, auth string) (any, error)
 	if err != nil {
 		return nil, err
 	}
+	if err := eJWT.checkRevocation(token); err != nil {
+		return nil, err
+	}
+	if err := eJWT.loadSession(c, token); err != nil {
+		return nil, err
+	}
 	if eJWT.Config.BeforeSuccessFunc != nil {
 		if err := eJWT.Config.BeforeSuccessFunc(token, c); err != nil {
 			return nil, err
@@ -99,16 +228,40 @@ func (eJWT EchoJWTUtil) ParseTokenFunc(c echo.Context, auth string) (any, error)
 }
 
 // JWTAuth returns a new instance of the echo-jwt middleware,
-// configured with the current EchoJWTConfig object
+// configured with the current EchoJWTConfig object.
+// When the token cookie is a TokenLookup source, unsafe requests
+// authenticated by the cookie also require the CSRF token.
 func (eJWT EchoJWTUtil) JWTAuth() echo.MiddlewareFunc {
-	return echojwt.WithConfig(eJWT.echoJWTConfig)
+	if !eJWT.usesTokenCookie() {
+		return echojwt.WithConfig(eJWT.echoJWTConfig)
+	}
+	config := eJWT.echoJWTConfig
+	config.ParseTokenFunc = func(c echo.Context, auth string) (any, error) {
+		if err := eJWT.checkCSRF(c, auth); err != nil {
+			return nil, err
+		}
+		return eJWT.ParseTokenFunc(c, auth)
+	}
+	jwtAuth := echojwt.WithConfig(config)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		h := jwtAuth(next)
+		return func(c echo.Context) error {
+			err := h(c)
+			var commonErr CommonError
+			if errors.As(err, &commonErr) && errors.Is(commonErr, ErrCSRFTokenInvalid) {
+				return commonErr
+			}
+			return err
+		}
+	}
 }
 
 // GetClaims retrieves and validates JWT claims.
-// It takes a JWT token and returns the converted claims
+// It takes a JWT token and returns the converted claims,
+// which are the embedded registered claims for custom claims
 func (eJWT EchoJWTUtil) GetClaims(token *jwt.Token) (*jwt.RegisteredClaims, error) {
-	claims, ok := token.Claims.(*jwt.RegisteredClaims)
-	if !ok {
+	claims := registeredClaimsOf(token.Claims)
+	if claims == nil {
 		return nil, fmt.Errorf("invalid token claims")
 	}
 	return claims, nil
@@ -0,0 +1,273 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/exp/slices"
+)
+
+// DefaultJWTContextKey is the echo.Context key of the token set by the echo-jwt middleware
+const DefaultJWTContextKey = "user"
+
+var (
+	ErrForbidden  = errors.New("insufficient permissions")     // Error for tokens lacking the required permissions.
+	ErrJWTMissing = errors.New("missing authentication token") // Error for requests without a token set by JWTAuth.
+)
+
+// Claim names read as scopes and roles, in order of precedence
+var (
+	jwtScopeClaimKeys = []string{"scope", "scp", "scopes"}
+	jwtRoleClaimKeys  = []string{"roles", "role"}
+)
+
+// JWTScopeClaims can be implemented by custom claims to provide their scopes.
+// Otherwise the scope, scp or scopes claim is read, as a space separated string or an array.
+type JWTScopeClaims interface {
+	GetScopes() []string
+}
+
+// JWTRoleClaims can be implemented by custom claims to provide their roles.
+// Otherwise the roles or role claim is read, as a space separated string or an array.
+type JWTRoleClaims interface {
+	GetRoles() []string
+}
+
+// EchoPolicyFunc decides whether the token of a request is allowed to access the route
+type EchoPolicyFunc func(c echo.Context, token *jwt.Token) (bool, error)
+
+// EchoPermission describes what a token needs to access a route.
+// All scopes are required, while any one of the roles is enough.
+type EchoPermission struct {
+	Scopes     []string       `json:"scopes,omitempty"` // Scopes the token must all have
+	Roles      []string       `json:"roles,omitempty"`  // Roles of which the token must have at least one
+	PolicyName string         `json:"policy,omitempty"` // Name of the policy, used for introspection
+	Policy     EchoPolicyFunc `json:"-"`                // Optional policy that must allow the request
+}
+
+// String returns a readable description of the permission
+func (p EchoPermission) String() string {
+	parts := []string{}
+	if len(p.Scopes) > 0 {
+		parts = append(parts, "scopes="+strings.Join(p.Scopes, ","))
+	}
+	if len(p.Roles) > 0 {
+		parts = append(parts, "roles="+strings.Join(p.Roles, "|"))
+	}
+	if p.Policy != nil || p.PolicyName != "" {
+		parts = append(parts, "policy="+p.PolicyName)
+	}
+	return strings.Join(parts, " ")
+}
+
+// EchoRoutePermission lists the permissions required by a route
+type EchoRoutePermission struct {
+	Method      string           `json:"method"`      // HTTP method of the route
+	Path        string           `json:"path"`        // Path of the route
+	Permissions []EchoPermission `json:"permissions"` // Permissions required by the route, empty for routes without authorization
+}
+
+// EchoRouter is implemented by *echo.Echo and *echo.Group
+type EchoRouter interface {
+	Add(method, path string, handler echo.HandlerFunc, middleware ...echo.MiddlewareFunc) *echo.Route
+	Group(prefix string, middleware ...echo.MiddlewareFunc) *echo.Group
+}
+
+// EchoAuthorizer provides authorization middleware reading the scopes and roles
+// of the token claims set by JWTAuth, and records the declared permissions per route
+type EchoAuthorizer struct {
+	echoJWT *EchoJWTUtil
+	mutex   sync.RWMutex
+	prefix  map[string][]EchoPermission
+	routes  map[string][]EchoPermission
+}
+
+// NewAuthorizer creates a new EchoAuthorizer for tokens of the EchoJWTUtil
+func (eJWT *EchoJWTUtil) NewAuthorizer() *EchoAuthorizer {
+	return &EchoAuthorizer{
+		echoJWT: eJWT,
+		prefix:  map[string][]EchoPermission{},
+		routes:  map[string][]EchoPermission{},
+	}
+}
+
+// RequireScopes returns a middleware requiring the token to have all the scopes
+func (a *EchoAuthorizer) RequireScopes(scopes ...string) echo.MiddlewareFunc {
+	return a.Require(EchoPermission{Scopes: scopes})
+}
+
+// RequireRoles returns a middleware requiring the token to have any of the roles
+func (a *EchoAuthorizer) RequireRoles(roles ...string) echo.MiddlewareFunc {
+	return a.Require(EchoPermission{Roles: roles})
+}
+
+// RequirePolicy returns a middleware requiring the policy to allow the request
+func (a *EchoAuthorizer) RequirePolicy(name string, policy EchoPolicyFunc) echo.MiddlewareFunc {
+	return a.Require(EchoPermission{PolicyName: name, Policy: policy})
+}
+
+// Require returns a middleware requiring the token to satisfy the permission.
+// It responds with ErrCodeUnauthorized without a token and ErrCodeForbidden without the permission.
+func (a *EchoAuthorizer) Require(p EchoPermission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err := a.authorize(c, p); err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// Group creates a route group on the router whose routes all require the permission
+func (a *EchoAuthorizer) Group(router EchoRouter, prefix string, p EchoPermission, m ...echo.MiddlewareFunc) *echo.Group {
+	g := router.Group(prefix, append([]echo.MiddlewareFunc{a.Require(p)}, m...)...)
+	// The group already registered this catch all route when using the middleware,
+	// registering it again only reveals the full prefix of nested groups
+	fullPrefix := g.RouteNotFound("", echo.NotFoundHandler).Path
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.prefix[fullPrefix] = append(a.prefix[fullPrefix], p)
+	return g
+}
+
+// Add registers a route on the router that requires the permission
+func (a *EchoAuthorizer) Add(router EchoRouter, method, path string, h echo.HandlerFunc, p EchoPermission, m ...echo.MiddlewareFunc) *echo.Route {
+	route := router.Add(method, path, h, append([]echo.MiddlewareFunc{a.Require(p)}, m...)...)
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	key := route.Method + " " + route.Path
+	a.routes[key] = append(a.routes[key], p)
+	return route
+}
+
+// Routes lists the routes of e with the permissions declared using Group and Add, sorted by path and method
+func (a *EchoAuthorizer) Routes(e *echo.Echo) []EchoRoutePermission {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	prefixes := make([]string, 0, len(a.prefix))
+	for prefix := range a.prefix {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		return len(prefixes[i]) < len(prefixes[j])
+	})
+	list := []EchoRoutePermission{}
+	for _, r := range e.Routes() {
+		if r.Method == echo.RouteNotFound {
+			continue
+		}
+		route := EchoRoutePermission{
+			Method:      r.Method,
+			Path:        r.Path,
+			Permissions: []EchoPermission{},
+		}
+		for _, prefix := range prefixes {
+			if r.Path == prefix || strings.HasPrefix(r.Path, strings.TrimSuffix(prefix, "/")+"/") {
+				route.Permissions = append(route.Permissions, a.prefix[prefix]...)
+			}
+		}
+		route.Permissions = append(route.Permissions, a.routes[r.Method+" "+r.Path]...)
+		list = append(list, route)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Path != list[j].Path {
+			return list[i].Path < list[j].Path
+		}
+		return list[i].Method < list[j].Method
+	})
+	return list
+}
+
+// authorize checks the permission against the token of the request
+func (a *EchoAuthorizer) authorize(c echo.Context, p EchoPermission) error {
+	key := a.echoJWT.echoJWTConfig.ContextKey
+	if key == "" {
+		key = DefaultJWTContextKey
+	}
+	token, ok := c.Get(key).(*jwt.Token)
+	if !ok {
+		return NewCommonErrorUnauthorized(ErrJWTMissing)
+	}
+	allowed := containsAll(ScopesOf(token.Claims), p.Scopes) &&
+		(len(p.Roles) == 0 || containsAny(RolesOf(token.Claims), p.Roles))
+	if allowed && p.Policy != nil {
+		var err error
+		if allowed, err = p.Policy(c, token); err != nil {
+			return err
+		}
+	}
+	if !allowed {
+		return NewCommonErrorForbidden(ErrForbidden)
+	}
+	return nil
+}
+
+// containsAll reports whether s contains all the values
+func containsAll(s []string, values []string) bool {
+	for _, v := range values {
+		if !slices.Contains(s, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsAny reports whether s contains any of the values
+func containsAny(s []string, values []string) bool {
+	for _, v := range values {
+		if slices.Contains(s, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopesOf returns the scopes of the token claims
+func ScopesOf(claims jwt.Claims) []string {
+	if c, ok := claims.(JWTScopeClaims); ok {
+		return c.GetScopes()
+	}
+	return claimStrings(claims, jwtScopeClaimKeys)
+}
+
+// RolesOf returns the roles of the token claims
+func RolesOf(claims jwt.Claims) []string {
+	if c, ok := claims.(JWTRoleClaims); ok {
+		return c.GetRoles()
+	}
+	return claimStrings(claims, jwtRoleClaimKeys)
+}
+
+// claimStrings returns the first of the named claims as a list of strings,
+// splitting space separated strings as used by the OAuth 2.0 scope claim
+func claimStrings(claims jwt.Claims, names []string) []string {
+	b, err := json.Marshal(claims)
+	if err != nil {
+		return nil
+	}
+	values := map[string]any{}
+	if err := json.Unmarshal(b, &values); err != nil {
+		return nil
+	}
+	for _, name := range names {
+		switch v := values[name].(type) {
+		case string:
+			return strings.Fields(v)
+		case []any:
+			list := make([]string, 0, len(v))
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					list = append(list, s)
+				}
+			}
+			return list
+		}
+	}
+	return nil
+}
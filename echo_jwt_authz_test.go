@@ -0,0 +1,97 @@
+package utils_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+type testAuthzClaims struct {
+	jwt.RegisteredClaims
+	Scope string   `json:"scope,omitempty"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+func TestScopesOf_RolesOf(t *testing.T) {
+	claims := &testAuthzClaims{Scope: "orders:read orders:write", Roles: []string{"admin"}}
+	assert.Equal(t, []string{"orders:read", "orders:write"}, utils.ScopesOf(claims))
+	assert.Equal(t, []string{"admin"}, utils.RolesOf(claims))
+	assert.Equal(t, []string{"a", "b"}, utils.ScopesOf(jwt.MapClaims{"scp": []any{"a", "b"}}))
+	assert.Empty(t, utils.RolesOf(&jwt.RegisteredClaims{}))
+}
+
+func TestEchoAuthorizer(t *testing.T) {
+	echoJWT := utils.NewEchoJWTClaims[testAuthzClaims](testEchoJWTConfig)
+	authz := echoJWT.NewAuthorizer()
+	e := echo.New()
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		resp := utils.ParseErrorResponse(err)
+		_ = c.JSON(resp.StatusCode, resp)
+	}
+	ok := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+	e.GET("/public", ok)
+	api := e.Group("/api", echoJWT.JWTAuth())
+	orders := authz.Group(api, "/orders", utils.EchoPermission{Scopes: []string{"orders:read"}})
+	orders.GET("", ok)
+	authz.Add(orders, http.MethodPost, "", ok, utils.EchoPermission{Scopes: []string{"orders:write"}})
+	api.GET("/admin", ok, authz.RequireRoles("admin", "owner"))
+	api.GET("/own/:id", ok, authz.RequirePolicy("owner", func(c echo.Context, token *jwt.Token) (bool, error) {
+		claims, _ := echoJWT.GetClaims(token)
+		return claims.Subject == c.Param("id"), nil
+	}))
+	e.GET("/no-token", ok, authz.RequireScopes("orders:read"))
+
+	serve := func(method, path string, claims testAuthzClaims) int {
+		token, _ := echoJWT.CreateToken(claims)
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", token.SignedString))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	reader := testAuthzClaims{RegisteredClaims: testClaims, Scope: "orders:read"}
+	writer := testAuthzClaims{RegisteredClaims: testClaims, Scope: "orders:read orders:write", Roles: []string{"owner"}}
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/orders", reader))
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPost, "/api/orders", reader))
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/orders", writer))
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodGet, "/api/orders", testAuthzClaims{RegisteredClaims: testClaims}))
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodGet, "/api/admin", reader))
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/admin", writer))
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/own/test-user", reader))
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodGet, "/api/own/other-user", reader))
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/no-token", reader))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/orders", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	token, _ := echoJWT.CreateToken(reader)
+	parsed, _ := echoJWT.ParseToken(token.SignedString)
+	c.Set(utils.DefaultJWTContextKey, parsed)
+	err := authz.RequireScopes("orders:write")(ok)(c)
+	resp := utils.ParseErrorResponse(err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, utils.ErrCodeForbidden, resp.ErrorCode)
+
+	routes := authz.Routes(e)
+	permissions := map[string]string{}
+	for _, r := range routes {
+		descriptions := []string{}
+		for _, p := range r.Permissions {
+			descriptions = append(descriptions, p.String())
+		}
+		permissions[r.Method+" "+r.Path] = fmt.Sprint(descriptions)
+	}
+	assert.Equal(t, "[]", permissions["GET /public"])
+	assert.Equal(t, "[scopes=orders:read]", permissions["GET /api/orders"])
+	assert.Equal(t, "[scopes=orders:read scopes=orders:write]", permissions["POST /api/orders"])
+}
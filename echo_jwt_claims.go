@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// ErrJWTClaimsNotRegistered is returned when custom claims do not embed jwt.RegisteredClaims
+var ErrJWTClaimsNotRegistered = errors.New("jwt claims must embed jwt.RegisteredClaims")
+
+// registeredClaimsType is the reflect.Type of jwt.RegisteredClaims
+var registeredClaimsType = reflect.TypeOf(jwt.RegisteredClaims{})
+
+// JWTClaims is the type constraint for custom claims used with EchoJWTClaimsUtil.
+// It is satisfied by a pointer to a struct embedding jwt.RegisteredClaims.
+type JWTClaims[C any] interface {
+	*C
+	jwt.Claims
+}
+
+// EchoJWTClaimsUtil is a variant of EchoJWTUtil operating on the custom claims type C,
+// e.g. a struct embedding jwt.RegisteredClaims together with roles or a tenant ID.
+// Tokens parsed by ParseToken and the JWTAuth middleware hold *C claims.
+type EchoJWTClaimsUtil[C any, PC JWTClaims[C]] struct {
+	*EchoJWTUtil
+}
+
+// JWTClaimsToken is a helper struct for returning signed JWT tokens with custom claims
+type JWTClaimsToken[C any] struct {
+	SignedString string // The signed token as a string
+	Claims       C      // The claims included in the token
+}
+
+// NewEchoJWTClaims creates and returns a new instance of EchoJWTClaimsUtil for the custom
+// claims type C, e.g. NewEchoJWTClaims[MyClaims](config)
+func NewEchoJWTClaims[C any, PC JWTClaims[C]](config *EchoJWTConfig) *EchoJWTClaimsUtil[C, PC] {
+	eJWT, _ := newEchoJWTClaims[C, PC](config)
+	return eJWT
+}
+
+// NewEchoJWTClaimsWithKeys creates and returns a new instance of EchoJWTClaimsUtil for the custom
+// claims type C, returning an error if a configured key or the encryption is invalid
+func NewEchoJWTClaimsWithKeys[C any, PC JWTClaims[C]](config *EchoJWTConfig) (*EchoJWTClaimsUtil[C, PC], error) {
+	eJWT, err := newEchoJWTClaims[C, PC](config)
+	if err != nil {
+		return nil, err
+	}
+	return eJWT, nil
+}
+
+// newEchoJWTClaims creates a new instance of EchoJWTClaimsUtil together with the error of the configured keys and encryption
+func newEchoJWTClaims[C any, PC JWTClaims[C]](config *EchoJWTConfig) (*EchoJWTClaimsUtil[C, PC], error) {
+	echoJWTUtil, err := newEchoJWTUtil(config, func() jwt.Claims {
+		return PC(new(C))
+	})
+	return &EchoJWTClaimsUtil[C, PC]{EchoJWTUtil: echoJWTUtil}, err
+}
+
+// CreateToken validates the custom claims using ValidateStruct,
+// fills the empty registered claims and returns a new JWTClaimsToken
+func (eJWT EchoJWTClaimsUtil[C, PC]) CreateToken(claims C) (JWTClaimsToken[C], error) {
+	if err := ValidateStruct(claims); err != nil {
+		return JWTClaimsToken[C]{}, err
+	}
+	registered := registeredClaimsOf(PC(&claims))
+	if registered == nil {
+		return JWTClaimsToken[C]{}, ErrJWTClaimsNotRegistered
+	}
+	eJWT.setDefaultClaims(registered)
+	signedToken, err := eJWT.signToken(PC(&claims))
+	if err != nil {
+		return JWTClaimsToken[C]{}, err
+	}
+	return JWTClaimsToken[C]{
+		SignedString: signedToken,
+		Claims:       claims,
+	}, nil
+}
+
+// GetClaims retrieves the custom claims of a token
+// parsed by ParseToken or the JWTAuth middleware
+func (eJWT EchoJWTClaimsUtil[C, PC]) GetClaims(token *jwt.Token) (*C, error) {
+	claims, ok := token.Claims.(PC)
+	if !ok {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+	return claims, nil
+}
+
+// registeredClaimsOf returns the jwt.RegisteredClaims of the given claims,
+// which are either registered claims or a pointer to a struct embedding them.
+// It returns nil if no registered claims are found.
+func registeredClaimsOf(claims any) *jwt.RegisteredClaims {
+	if c, ok := claims.(*jwt.RegisteredClaims); ok {
+		return c
+	}
+	if f, ok := embeddedFieldOf(claims, registeredClaimsType); ok {
+		return f.Interface().(*jwt.RegisteredClaims)
+	}
+	return nil
+}
+
+// embeddedFieldOf returns a pointer to the field of type t embedded
+// in the struct pointed to by v, searching embedded structs recursively
+func embeddedFieldOf(v any, t reflect.Type) (reflect.Value, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return reflect.Value{}, false
+	}
+	return findEmbeddedField(rv.Elem(), t)
+}
+
+// findEmbeddedField searches the embedded fields of a struct value for the type t
+func findEmbeddedField(v reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.Anonymous {
+			continue
+		}
+		f := v.Field(i)
+		if f.Kind() == reflect.Ptr {
+			if f.IsNil() {
+				continue
+			}
+			f = f.Elem()
+		}
+		if f.Type() == t {
+			if !f.CanAddr() {
+				return reflect.Value{}, false
+			}
+			return f.Addr(), true
+		}
+		if found, ok := findEmbeddedField(f, t); ok {
+			return found, true
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,109 @@
+package utils_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+type testCustomClaims struct {
+	jwt.RegisteredClaims
+	TenantID string   `json:"tid" validate:"required"`
+	Roles    []string `json:"roles,omitempty"`
+}
+
+func TestEchoJWTClaimsUtil_CreateToken(t *testing.T) {
+	echoJWT := utils.NewEchoJWTClaims[testCustomClaims](testEchoJWTConfig)
+
+	t.Run("success", func(t *testing.T) {
+		claims := testCustomClaims{
+			RegisteredClaims: testClaims,
+			TenantID:         "tenant-1",
+			Roles:            []string{"admin"},
+		}
+		token, err := echoJWT.CreateToken(claims)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token.SignedString)
+		assert.NotEmpty(t, token.Claims.ID)
+		assert.NotNil(t, token.Claims.ExpiresAt)
+		assert.Equal(t, "tenant-1", token.Claims.TenantID)
+	})
+
+	t.Run("missing required custom claim", func(t *testing.T) {
+		_, err := echoJWT.CreateToken(testCustomClaims{RegisteredClaims: testClaims})
+		assert.EqualError(t, err, "Validation failed for 'tid'")
+	})
+}
+
+func TestEchoJWTClaimsUtil_ParseToken(t *testing.T) {
+	echoJWT := utils.NewEchoJWTClaims[testCustomClaims](testEchoJWTConfig)
+
+	t.Run("success", func(t *testing.T) {
+		token, _ := echoJWT.CreateToken(testCustomClaims{
+			RegisteredClaims: testClaims,
+			TenantID:         "tenant-1",
+			Roles:            []string{"admin"},
+		})
+		result, err := echoJWT.ParseToken(token.SignedString)
+		assert.NoError(t, err)
+		claims, err := echoJWT.GetClaims(result)
+		assert.NoError(t, err)
+		assert.Equal(t, token.Claims, *claims)
+		registered, err := echoJWT.EchoJWTUtil.GetClaims(result)
+		assert.NoError(t, err)
+		assert.Equal(t, &claims.RegisteredClaims, registered)
+	})
+
+	t.Run("missing required custom claim", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims)
+		signedToken, _ := token.SignedString([]byte(testEchoJWTConfig.SigningKey))
+		result, err := echoJWT.ParseToken(signedToken)
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "Validation failed for 'tid'")
+	})
+
+	t.Run("invalid claims type", func(t *testing.T) {
+		result, err := echoJWT.GetClaims(&jwt.Token{Claims: &jwt.RegisteredClaims{}})
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "invalid token claims")
+	})
+}
+
+func TestEchoJWTClaimsUtil_CreateToken_NotRegistered(t *testing.T) {
+	type mapClaims struct {
+		jwt.MapClaims
+	}
+	echoJWT := utils.NewEchoJWTClaims[mapClaims](testEchoJWTConfig)
+	_, err := echoJWT.CreateToken(mapClaims{MapClaims: jwt.MapClaims{}})
+	assert.ErrorIs(t, err, utils.ErrJWTClaimsNotRegistered)
+}
+
+func TestEchoJWTClaimsUtil_JWTAuth(t *testing.T) {
+	echoJWT := utils.NewEchoJWTClaims[testCustomClaims](testEchoJWTConfig)
+	e := echo.New()
+	e.GET("/", func(c echo.Context) error {
+		claims, err := echoJWT.GetClaims(c.Get("user").(*jwt.Token))
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, claims.Subject+":"+claims.TenantID)
+	})
+	e.Use(echoJWT.JWTAuth())
+	token, _ := echoJWT.CreateToken(testCustomClaims{
+		RegisteredClaims: testClaims,
+		TenantID:         "tenant-1",
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", token.SignedString))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "test-user:tenant-1", rec.Body.String())
+}
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+
+	echojwt "github.com/labstack/echo-jwt/v4"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/exp/slices"
+)
+
+// Default values for JWTCookieConfig
+const (
+	DefaultJWTCookieName      = "token"
+	DefaultJWTCookiePath      = "/"
+	DefaultJWTCSRFCookieName  = "_csrf"
+	DefaultJWTCSRFHeaderName  = echo.HeaderXCSRFToken
+	defaultJWTCSRFTokenLength = 32
+)
+
+// ErrCSRFTokenInvalid is returned when a request authenticated by the token cookie has no matching CSRF token
+var ErrCSRFTokenInvalid = errors.New("invalid csrf token")
+
+// JWTCookieConfig is the configuration of the cookie used to store the token,
+// read by JWTAuth when the TokenLookup of EchoJWTConfig contains "cookie:<Name>"
+type JWTCookieConfig struct {
+	Name           string        // The name of the token cookie, defaults to DefaultJWTCookieName
+	Domain         string        // The domain of the cookies
+	Path           string        // The path of the cookies, defaults to DefaultJWTCookiePath
+	SameSite       http.SameSite // The SameSite mode of the cookies, defaults to http.SameSiteLaxMode
+	Insecure       bool          // Whether to omit the Secure flag, e.g. for local development
+	CSRFCookieName string        // The name of the CSRF cookie, defaults to DefaultJWTCSRFCookieName
+	CSRFHeaderName string        // The request header holding the CSRF token, defaults to DefaultJWTCSRFHeaderName
+}
+
+// withDefaults returns the cookie configuration with defaults for empty fields
+func (config JWTCookieConfig) withDefaults() JWTCookieConfig {
+	if config.Name == "" {
+		config.Name = DefaultJWTCookieName
+	}
+	if config.Path == "" {
+		config.Path = DefaultJWTCookiePath
+	}
+	if config.SameSite == 0 {
+		config.SameSite = http.SameSiteLaxMode
+	}
+	if config.CSRFCookieName == "" {
+		config.CSRFCookieName = DefaultJWTCSRFCookieName
+	}
+	if config.CSRFHeaderName == "" {
+		config.CSRFHeaderName = DefaultJWTCSRFHeaderName
+	}
+	return config
+}
+
+// SetTokenCookie sets the signed token as a HttpOnly cookie expiring after ExpiresTTL,
+// together with a CSRF cookie readable by scripts for double submit protection.
+// It returns the CSRF token to send in the CSRF header of unsafe requests.
+func (eJWT EchoJWTUtil) SetTokenCookie(c echo.Context, signedToken string) (string, error) {
+	config := eJWT.Config.Cookie.withDefaults()
+	b := make([]byte, defaultJWTCSRFTokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	csrfToken := base64.RawURLEncoding.EncodeToString(b)
+	maxAge := int(eJWT.Config.ExpiresTTL.Seconds())
+	c.SetCookie(eJWT.newCookie(config, config.Name, signedToken, maxAge, true))
+	c.SetCookie(eJWT.newCookie(config, config.CSRFCookieName, csrfToken, maxAge, false))
+	return csrfToken, nil
+}
+
+// ClearTokenCookie removes the token and CSRF cookies, e.g. on logout
+func (eJWT EchoJWTUtil) ClearTokenCookie(c echo.Context) {
+	config := eJWT.Config.Cookie.withDefaults()
+	c.SetCookie(eJWT.newCookie(config, config.Name, "", -1, true))
+	c.SetCookie(eJWT.newCookie(config, config.CSRFCookieName, "", -1, false))
+}
+
+// newCookie creates a cookie using the cookie configuration
+func (eJWT EchoJWTUtil) newCookie(config JWTCookieConfig, name, value string, maxAge int, httpOnly bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Domain:   config.Domain,
+		Path:     config.Path,
+		MaxAge:   maxAge,
+		Secure:   !config.Insecure,
+		HttpOnly: httpOnly,
+		SameSite: config.SameSite,
+	}
+}
+
+// usesTokenCookie reports whether the TokenLookup reads the token cookie
+func (eJWT EchoJWTUtil) usesTokenCookie() bool {
+	name := eJWT.Config.Cookie.withDefaults().Name
+	for _, source := range strings.Split(eJWT.Config.TokenLookup, ",") {
+		if strings.TrimSpace(source) == "cookie:"+name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCSRF verifies the double submitted CSRF token of unsafe requests
+// authenticated by the token cookie rather than by a header
+func (eJWT EchoJWTUtil) checkCSRF(c echo.Context, auth string) error {
+	req := c.Request()
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return nil
+	}
+	config := eJWT.Config.Cookie.withDefaults()
+	if tokenCookie, err := c.Cookie(config.Name); err != nil || tokenCookie.Value != auth {
+		return nil
+	}
+	if eJWT.isHeaderToken(c, auth) {
+		return nil
+	}
+	cookie, err := c.Cookie(config.CSRFCookieName)
+	header := req.Header.Get(config.CSRFHeaderName)
+	if err != nil || cookie.Value == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(header)) != 1 {
+		return NewCommonErrorForbidden(ErrCSRFTokenInvalid)
+	}
+	return nil
+}
+
+// isHeaderToken reports whether a header source of the TokenLookup holds the token,
+// which cross-site requests cannot set
+func (eJWT EchoJWTUtil) isHeaderToken(c echo.Context, auth string) bool {
+	for _, source := range strings.Split(eJWT.Config.TokenLookup, ",") {
+		if !strings.HasPrefix(source, "header:") {
+			continue
+		}
+		extractors, err := echojwt.CreateExtractors(source)
+		if err != nil {
+			continue
+		}
+		for _, extractor := range extractors {
+			values, _ := extractor(c)
+			if slices.Contains(values, auth) {
+				return true
+			}
+		}
+	}
+	return false
+}
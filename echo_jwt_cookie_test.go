@@ -0,0 +1,118 @@
+package utils_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+func TestEchoJWTUtil_SetTokenCookie(t *testing.T) {
+	echoJWT := utils.EchoJWT.New(&utils.EchoJWTConfig{
+		SigningKey: "my-secret-key",
+		ExpiresTTL: time.Hour,
+		Cookie:     utils.JWTCookieConfig{Domain: "example.com", SameSite: http.SameSiteStrictMode},
+	})
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/login", nil), rec)
+	csrfToken, err := echoJWT.SetTokenCookie(c, "signed-token")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, csrfToken)
+	cookies := rec.Result().Cookies()
+	assert.Len(t, cookies, 2)
+	assert.Equal(t, utils.DefaultJWTCookieName, cookies[0].Name)
+	assert.Equal(t, "signed-token", cookies[0].Value)
+	assert.Equal(t, "example.com", cookies[0].Domain)
+	assert.Equal(t, 3600, cookies[0].MaxAge)
+	assert.Equal(t, http.SameSiteStrictMode, cookies[0].SameSite)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.True(t, cookies[0].Secure)
+	assert.Equal(t, utils.DefaultJWTCSRFCookieName, cookies[1].Name)
+	assert.Equal(t, csrfToken, cookies[1].Value)
+	assert.False(t, cookies[1].HttpOnly)
+
+	rec = httptest.NewRecorder()
+	c = echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/logout", nil), rec)
+	echoJWT.ClearTokenCookie(c)
+	for _, cookie := range rec.Result().Cookies() {
+		assert.Empty(t, cookie.Value)
+		assert.Equal(t, -1, cookie.MaxAge)
+	}
+}
+
+func TestEchoJWTUtil_JWTAuth_TokenLookup(t *testing.T) {
+	echoJWT := utils.EchoJWT.New(&utils.EchoJWTConfig{
+		SigningKey:  "my-secret-key",
+		ExpiresTTL:  time.Hour,
+		TokenLookup: "header:Authorization:Bearer ,cookie:token,query:token",
+	})
+	e := echo.New()
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		resp := utils.ParseErrorResponse(err)
+		_ = c.JSON(resp.StatusCode, resp)
+	}
+	e.Use(echoJWT.JWTAuth())
+	ok := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+	e.GET("/", ok)
+	e.POST("/", ok)
+	token := echoJWT.CreateToken(testClaims)
+	serve := func(req *http.Request) int {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	t.Run("header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token.SignedString)
+		assert.Equal(t, http.StatusOK, serve(req))
+	})
+
+	t.Run("query", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?token="+token.SignedString, nil)
+		assert.Equal(t, http.StatusOK, serve(req))
+	})
+
+	t.Run("cookie", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "token", Value: token.SignedString})
+		assert.Equal(t, http.StatusOK, serve(req))
+	})
+
+	t.Run("cookie without csrf", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "token", Value: token.SignedString})
+		req.AddCookie(&http.Cookie{Name: utils.DefaultJWTCSRFCookieName, Value: "csrf"})
+		assert.Equal(t, http.StatusForbidden, serve(req))
+	})
+
+	t.Run("cookie with csrf", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "token", Value: token.SignedString})
+		req.AddCookie(&http.Cookie{Name: utils.DefaultJWTCSRFCookieName, Value: "csrf"})
+		req.Header.Set(utils.DefaultJWTCSRFHeaderName, "csrf")
+		assert.Equal(t, http.StatusOK, serve(req))
+	})
+
+	t.Run("cookie with invalid header without csrf", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer invalid")
+		req.AddCookie(&http.Cookie{Name: "token", Value: token.SignedString})
+		req.AddCookie(&http.Cookie{Name: utils.DefaultJWTCSRFCookieName, Value: "csrf"})
+		assert.Equal(t, http.StatusForbidden, serve(req))
+	})
+
+	t.Run("header with cookie without csrf", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token.SignedString)
+		req.AddCookie(&http.Cookie{Name: "token", Value: token.SignedString})
+		assert.Equal(t, http.StatusOK, serve(req))
+	})
+}
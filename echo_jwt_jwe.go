@@ -0,0 +1,218 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1" // #nosec
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"hash"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// JWE key management and content encryption algorithms
+const (
+	JWEAlgorithmDir        = "dir"
+	JWEAlgorithmRSAOAEP    = "RSA-OAEP"
+	JWEAlgorithmRSAOAEP256 = "RSA-OAEP-256"
+	JWEEncryptionA256GCM   = "A256GCM"
+)
+
+// jweKeySize is the content encryption key size of A256GCM in bytes
+const jweKeySize = 32
+
+var (
+	ErrJWEInvalid     = errors.New("invalid encrypted token")   // Error for encrypted tokens that cannot be decrypted.
+	ErrJWERequired    = errors.New("token must be encrypted")   // Error for plain tokens when encryption is required.
+	ErrJWEUnsupported = errors.New("unsupported jwe algorithm") // Error for unsupported key management algorithms.
+	ErrJWEKey         = errors.New("invalid jwe key")           // Error for missing keys or keys of invalid size.
+)
+
+// JWTEncryptionConfig is the configuration for encrypting signed tokens as JWE compact tokens
+type JWTEncryptionConfig struct {
+	Algorithm  string          // The key management algorithm: JWEAlgorithmDir, JWEAlgorithmRSAOAEP or JWEAlgorithmRSAOAEP256
+	Key        []byte          // The 256 bit shared key used with JWEAlgorithmDir
+	PublicKey  *rsa.PublicKey  // The public key wrapping the content key, defaults to the public key of PrivateKey
+	PrivateKey *rsa.PrivateKey // The private key unwrapping the content key
+	KeyID      string          // The optional kid header of encrypted tokens
+	Required   bool            // Whether to reject tokens that are not encrypted
+}
+
+// jweHeader is the protected header of a JWE compact token
+type jweHeader struct {
+	Algorithm   string `json:"alg"`
+	Encryption  string `json:"enc"`
+	ContentType string `json:"cty,omitempty"`
+	KeyID       string `json:"kid,omitempty"`
+}
+
+// encryptToken encrypts a signed token as a JWE compact token using A256GCM
+func (config JWTEncryptionConfig) encryptToken(signedToken string) (string, error) {
+	cek, encryptedKey, err := config.newContentKey()
+	if err != nil {
+		return "", err
+	}
+	header, _ := json.Marshal(jweHeader{
+		Algorithm:   config.Algorithm,
+		Encryption:  JWEEncryptionA256GCM,
+		ContentType: "JWT",
+		KeyID:       config.KeyID,
+	})
+	protected := base64.RawURLEncoding.EncodeToString(header)
+	gcm, err := newAESGCM(cek)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nil, iv, []byte(signedToken), []byte(protected))
+	tagStart := len(sealed) - gcm.Overhead()
+	return strings.Join([]string{
+		protected,
+		base64.RawURLEncoding.EncodeToString(encryptedKey),
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(sealed[:tagStart]),
+		base64.RawURLEncoding.EncodeToString(sealed[tagStart:]),
+	}, "."), nil
+}
+
+// decryptToken decrypts a JWE compact token and returns the signed token.
+// Tokens that are not encrypted are returned as is unless encryption is required.
+func (config JWTEncryptionConfig) decryptToken(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		if config.Required {
+			return "", &jwt.ValidationError{Inner: ErrJWERequired, Errors: jwt.ValidationErrorMalformed}
+		}
+		return token, nil
+	}
+	signedToken, err := config.decryptParts(parts)
+	if err != nil {
+		return "", &jwt.ValidationError{Inner: ErrJWEInvalid, Errors: jwt.ValidationErrorUnverifiable}
+	}
+	return signedToken, nil
+}
+
+// decryptParts decrypts the five parts of a JWE compact token
+func (config JWTEncryptionConfig) decryptParts(parts []string) (string, error) {
+	decoded := make([][]byte, len(parts))
+	for i, part := range parts {
+		b, err := base64.RawURLEncoding.DecodeString(part)
+		if err != nil {
+			return "", err
+		}
+		decoded[i] = b
+	}
+	header := jweHeader{}
+	if err := json.Unmarshal(decoded[0], &header); err != nil {
+		return "", err
+	}
+	if header.Algorithm != config.Algorithm || header.Encryption != JWEEncryptionA256GCM {
+		return "", ErrJWEUnsupported
+	}
+	cek, err := config.contentKey(decoded[1])
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newAESGCM(cek)
+	if err != nil {
+		return "", err
+	}
+	if len(decoded[2]) != gcm.NonceSize() || len(decoded[4]) != gcm.Overhead() {
+		return "", ErrJWEInvalid
+	}
+	plaintext, err := gcm.Open(nil, decoded[2], append(decoded[3], decoded[4]...), []byte(parts[0]))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// validate checks the key management algorithm and the size of the keys
+func (config JWTEncryptionConfig) validate() error {
+	switch config.Algorithm {
+	case JWEAlgorithmDir:
+		if len(config.Key) != jweKeySize {
+			return ErrJWEKey
+		}
+	case JWEAlgorithmRSAOAEP, JWEAlgorithmRSAOAEP256:
+		if config.PublicKey == nil && config.PrivateKey == nil {
+			return ErrJWEKey
+		}
+	default:
+		return ErrJWEUnsupported
+	}
+	return nil
+}
+
+// newContentKey returns a content encryption key and its encrypted form for the JWE token
+func (config JWTEncryptionConfig) newContentKey() ([]byte, []byte, error) {
+	if config.Algorithm == JWEAlgorithmDir {
+		if len(config.Key) != jweKeySize {
+			return nil, nil, ErrJWEKey
+		}
+		return config.Key, []byte{}, nil
+	}
+	h, err := config.oaepHash()
+	if err != nil {
+		return nil, nil, err
+	}
+	publicKey := config.PublicKey
+	if publicKey == nil && config.PrivateKey != nil {
+		publicKey = &config.PrivateKey.PublicKey
+	}
+	if publicKey == nil {
+		return nil, nil, ErrJWEKey
+	}
+	cek := make([]byte, jweKeySize)
+	if _, err := rand.Read(cek); err != nil {
+		return nil, nil, err
+	}
+	encryptedKey, err := rsa.EncryptOAEP(h, rand.Reader, publicKey, cek, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cek, encryptedKey, nil
+}
+
+// contentKey returns the content encryption key from the encrypted key of a JWE token
+func (config JWTEncryptionConfig) contentKey(encryptedKey []byte) ([]byte, error) {
+	if config.Algorithm == JWEAlgorithmDir {
+		if len(encryptedKey) != 0 || len(config.Key) != jweKeySize {
+			return nil, ErrJWEKey
+		}
+		return config.Key, nil
+	}
+	h, err := config.oaepHash()
+	if err != nil {
+		return nil, err
+	}
+	if config.PrivateKey == nil {
+		return nil, ErrJWEKey
+	}
+	cek, err := rsa.DecryptOAEP(h, nil, config.PrivateKey, encryptedKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(cek) != jweKeySize {
+		return nil, ErrJWEKey
+	}
+	return cek, nil
+}
+
+// oaepHash returns the hash used by the RSA-OAEP key management algorithm
+func (config JWTEncryptionConfig) oaepHash() (hash.Hash, error) {
+	switch config.Algorithm {
+	case JWEAlgorithmRSAOAEP:
+		return sha1.New(), nil // #nosec
+	case JWEAlgorithmRSAOAEP256:
+		return sha256.New(), nil
+	}
+	return nil, ErrJWEUnsupported
+}
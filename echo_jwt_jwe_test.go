@@ -0,0 +1,141 @@
+package utils_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+func TestEchoJWTUtil_Encryption(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	tests := []struct {
+		name       string
+		encryption utils.JWTEncryptionConfig
+	}{
+		{
+			name:       "dir",
+			encryption: utils.JWTEncryptionConfig{Algorithm: utils.JWEAlgorithmDir, Key: []byte("0123456789abcdef0123456789abcdef")},
+		},
+		{
+			name:       "RSA-OAEP",
+			encryption: utils.JWTEncryptionConfig{Algorithm: utils.JWEAlgorithmRSAOAEP, PrivateKey: rsaKey, KeyID: "enc-1"},
+		},
+		{
+			name:       "RSA-OAEP-256",
+			encryption: utils.JWTEncryptionConfig{Algorithm: utils.JWEAlgorithmRSAOAEP256, PrivateKey: rsaKey},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encryption := test.encryption
+			echoJWT := utils.EchoJWT.New(&utils.EchoJWTConfig{
+				SigningKey: "my-secret-key",
+				ExpiresTTL: time.Hour,
+				Encryption: &encryption,
+			})
+			token := echoJWT.CreateToken(testClaims)
+			assert.Len(t, strings.Split(token.SignedString, "."), 5)
+			assert.NotContains(t, token.SignedString, "test-user")
+
+			result, err := echoJWT.ParseToken(token.SignedString)
+			assert.NoError(t, err)
+			claims, _ := echoJWT.GetClaims(result)
+			assert.Equal(t, "test-user", claims.Subject)
+
+			parts := strings.Split(token.SignedString, ".")
+			parts[3] = parts[3][:len(parts[3])-2] + "AA"
+			_, err = echoJWT.ParseToken(strings.Join(parts, "."))
+			assert.ErrorIs(t, err, utils.ErrJWEInvalid)
+			assert.Equal(t, utils.ErrCodeUnauthorizedTokenInvalid, utils.ParseErrorResponse(err).ErrorCode)
+		})
+	}
+}
+
+func TestEchoJWTUtil_Encryption_Required(t *testing.T) {
+	encryption := &utils.JWTEncryptionConfig{Algorithm: utils.JWEAlgorithmDir, Key: []byte("0123456789abcdef0123456789abcdef")}
+	echoJWT := utils.EchoJWT.New(&utils.EchoJWTConfig{
+		SigningKey: "my-secret-key",
+		ExpiresTTL: time.Hour,
+		Encryption: encryption,
+	})
+	plain := utils.EchoJWT.New(testEchoJWTConfig).CreateToken(testClaims)
+	_, err := echoJWT.ParseToken(plain.SignedString)
+	assert.NoError(t, err)
+
+	encryption.Required = true
+	_, err = echoJWT.ParseToken(plain.SignedString)
+	assert.ErrorIs(t, err, utils.ErrJWERequired)
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	other := utils.EchoJWT.New(&utils.EchoJWTConfig{
+		SigningKey: "my-secret-key",
+		ExpiresTTL: time.Hour,
+		Encryption: &utils.JWTEncryptionConfig{Algorithm: utils.JWEAlgorithmRSAOAEP256, PrivateKey: rsaKey},
+	})
+	_, err = echoJWT.ParseToken(other.CreateToken(testClaims).SignedString)
+	assert.ErrorIs(t, err, utils.ErrJWEInvalid)
+}
+
+func TestEchoJWTUtil_Encryption_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		encryption utils.JWTEncryptionConfig
+		err        error
+	}{
+		{"short dir key", utils.JWTEncryptionConfig{Algorithm: utils.JWEAlgorithmDir, Key: []byte("short")}, utils.ErrJWEKey},
+		{"missing rsa key", utils.JWTEncryptionConfig{Algorithm: utils.JWEAlgorithmRSAOAEP}, utils.ErrJWEKey},
+		{"unsupported algorithm", utils.JWTEncryptionConfig{Algorithm: "A256KW"}, utils.ErrJWEUnsupported},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := &utils.EchoJWTConfig{SigningKey: "my-secret-key", ExpiresTTL: time.Hour, Encryption: &test.encryption}
+			_, err := utils.EchoJWT.NewWithKeys(config)
+			assert.ErrorIs(t, err, test.err)
+			token, err := utils.EchoJWT.New(config).CreateTokenWithError(testClaims)
+			assert.ErrorIs(t, err, test.err)
+			assert.Empty(t, token.SignedString)
+		})
+	}
+}
+
+func TestEchoJWTUtil_CreateTokenWithError_VerifyOnly(t *testing.T) {
+	echoJWT := utils.EchoJWT.New(&utils.EchoJWTConfig{
+		Keys:       []utils.JWTKey{{ID: "rsa", Method: jwt.SigningMethodRS256, PublicKey: &testRSAKey.PublicKey}},
+		ExpiresTTL: time.Hour,
+	})
+	_, err := echoJWT.CreateTokenWithError(testClaims)
+	assert.ErrorIs(t, err, utils.ErrJWTKeyNotFound)
+}
+
+func TestEchoJWTUtil_Encryption_JWTAuth(t *testing.T) {
+	echoJWT := utils.NewEchoJWTClaims[testCustomClaims](&utils.EchoJWTConfig{
+		SigningKey: "my-secret-key",
+		ExpiresTTL: time.Hour,
+		Encryption: &utils.JWTEncryptionConfig{Algorithm: utils.JWEAlgorithmDir, Key: []byte("0123456789abcdef0123456789abcdef")},
+	})
+	e := echo.New()
+	e.Use(echoJWT.JWTAuth())
+	e.GET("/", func(c echo.Context) error {
+		claims, _ := echoJWT.GetClaims(c.Get("user").(*jwt.Token))
+		return c.String(http.StatusOK, claims.TenantID)
+	})
+	token, err := echoJWT.CreateToken(testCustomClaims{RegisteredClaims: testClaims, TenantID: "tenant-1"})
+	assert.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token.SignedString)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "tenant-1", rec.Body.String())
+}
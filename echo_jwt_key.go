@@ -0,0 +1,318 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// JWKSPath is the well-known path of the JWKS endpoint
+const JWKSPath = "/.well-known/jwks.json"
+
+var (
+	ErrJWTKeyNotFound      = errors.New("jwt key not found")                 // Error for when a key ID is not registered.
+	ErrJWTKeyInvalid       = errors.New("invalid jwt key")                   // Error for when a key does not match its signing method.
+	ErrJWTKeyPEM           = errors.New("invalid jwt key pem")               // Error for when a PEM block cannot be decoded.
+	ErrJWTKeyInUse         = errors.New("jwt key is the signing key")        // Error for removing the current signing key.
+	ErrJWTKeySetNotCreated = errors.New("jwt util was not created with New") // Error for changing the keys of a util not created with New.
+)
+
+// JWTKey is a key used to sign and verify JWT tokens, identified by the kid header
+type JWTKey struct {
+	ID         string            // Key ID written to the kid header of signed tokens
+	Method     jwt.SigningMethod // Signing method, e.g. jwt.SigningMethodRS256
+	PrivateKey crypto.PrivateKey // Key used to sign tokens ([]byte for HMAC), nil for verification only keys
+	PublicKey  crypto.PublicKey  // Key used to verify tokens, derived from PrivateKey when nil
+}
+
+// JWK is a JSON Web Key as defined in RFC 7517
+type JWK struct {
+	KeyType   string `json:"kty"`           // Key type: RSA, EC or OKP
+	KeyID     string `json:"kid,omitempty"` // Key ID
+	Use       string `json:"use,omitempty"` // Public key use
+	Algorithm string `json:"alg,omitempty"` // Signing algorithm
+	Curve     string `json:"crv,omitempty"` // Curve of EC and OKP keys
+	N         string `json:"n,omitempty"`   // Modulus of RSA keys
+	E         string `json:"e,omitempty"`   // Exponent of RSA keys
+	X         string `json:"x,omitempty"`   // X coordinate of EC keys, public key of OKP keys
+	Y         string `json:"y,omitempty"`   // Y coordinate of EC keys
+}
+
+// JWKSet is a JSON Web Key Set as defined in RFC 7517
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// jwtKeySet holds the keys of an EchoJWTUtil, shared by its copies
+type jwtKeySet struct {
+	mutex        sync.RWMutex
+	keys         map[string]JWTKey
+	signingKeyID string
+}
+
+// ParseKeyPEM creates a JWTKey from a PEM encoded private or public key.
+// Private keys may be PKCS #1, PKCS #8 or SEC 1 encoded, public keys PKIX
+// encoded or wrapped in a certificate. A public key only verifies tokens.
+func (EchoJWTUtil) ParseKeyPEM(id, alg string, data []byte) (JWTKey, error) {
+	method := jwt.GetSigningMethod(alg)
+	if method == nil {
+		return JWTKey{}, fmt.Errorf("unexpected jwt signing method=%v", alg)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return JWTKey{}, ErrJWTKeyPEM
+	}
+	key := JWTKey{ID: id, Method: method}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key.PrivateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key.PrivateKey, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key.PrivateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key.PublicKey, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		key.PublicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			key.PublicKey = cert.PublicKey
+		}
+	default:
+		return JWTKey{}, ErrJWTKeyPEM
+	}
+	if err != nil {
+		return JWTKey{}, err
+	}
+	if err := key.validate(); err != nil {
+		return JWTKey{}, err
+	}
+	return key, nil
+}
+
+// AddKey adds or replaces a key used to verify tokens,
+// so signing keys can be rotated without invalidating existing tokens.
+func (eJWT EchoJWTUtil) AddKey(key JWTKey) error {
+	if err := key.validate(); err != nil {
+		return err
+	}
+	keySet, err := eJWT.mutableKeySet()
+	if err != nil {
+		return err
+	}
+	keySet.mutex.Lock()
+	defer keySet.mutex.Unlock()
+	keySet.keys[key.ID] = key
+	return nil
+}
+
+// RemoveKey removes a key, tokens signed with it are no longer valid.
+// The current signing key cannot be removed.
+func (eJWT EchoJWTUtil) RemoveKey(id string) error {
+	keySet, err := eJWT.mutableKeySet()
+	if err != nil {
+		return err
+	}
+	keySet.mutex.Lock()
+	defer keySet.mutex.Unlock()
+	if _, ok := keySet.keys[id]; !ok {
+		return ErrJWTKeyNotFound
+	}
+	if id == keySet.signingKeyID {
+		return ErrJWTKeyInUse
+	}
+	delete(keySet.keys, id)
+	return nil
+}
+
+// SetSigningKey sets the key used to sign new tokens.
+// The key must have been added and have a private key.
+func (eJWT EchoJWTUtil) SetSigningKey(id string) error {
+	keySet, err := eJWT.mutableKeySet()
+	if err != nil {
+		return err
+	}
+	keySet.mutex.Lock()
+	defer keySet.mutex.Unlock()
+	key, ok := keySet.keys[id]
+	if !ok || key.PrivateKey == nil {
+		return ErrJWTKeyNotFound
+	}
+	keySet.signingKeyID = id
+	return nil
+}
+
+// JWKS returns the public keys of the asymmetric keys, sorted by key ID.
+// HMAC keys are secret and never included.
+func (eJWT EchoJWTUtil) JWKS() JWKSet {
+	jwks := JWKSet{Keys: []JWK{}}
+	keySet, err := eJWT.getKeySet()
+	if err != nil {
+		return jwks
+	}
+	keySet.mutex.RLock()
+	defer keySet.mutex.RUnlock()
+	for _, key := range keySet.keys {
+		if jwk, ok := key.jwk(); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].KeyID < jwks.Keys[j].KeyID
+	})
+	return jwks
+}
+
+// JWKSHandler handles requests to the JWKSPath endpoint
+func (eJWT EchoJWTUtil) JWKSHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, eJWT.JWKS())
+}
+
+// getKeySet returns the key set of the instance, or a read-only key set derived
+// from the configuration if the instance was not created with New
+func (eJWT EchoJWTUtil) getKeySet() (*jwtKeySet, error) {
+	if eJWT.configErr != nil {
+		return nil, eJWT.configErr
+	}
+	if eJWT.keySet != nil {
+		return eJWT.keySet, nil
+	}
+	return newJWTKeySet(eJWT.Config)
+}
+
+// mutableKeySet returns the key set of the instance, which can only
+// be changed if the instance was created with New
+func (eJWT EchoJWTUtil) mutableKeySet() (*jwtKeySet, error) {
+	if eJWT.configErr != nil {
+		return nil, eJWT.configErr
+	}
+	if eJWT.keySet == nil {
+		return nil, ErrJWTKeySetNotCreated
+	}
+	return eJWT.keySet, nil
+}
+
+// newJWTKeySet creates a key set from the configured keys, validating them. SigningKey is
+// registered as an HS256 key without ID, so tokens without a kid header remain valid.
+func newJWTKeySet(config *EchoJWTConfig) (*jwtKeySet, error) {
+	keySet := &jwtKeySet{keys: make(map[string]JWTKey)}
+	if config.SigningKey != "" || len(config.Keys) == 0 {
+		keySet.keys[""] = JWTKey{
+			Method:     jwt.SigningMethodHS256,
+			PrivateKey: []byte(config.SigningKey),
+		}
+	}
+	for _, key := range config.Keys {
+		if err := key.validate(); err != nil {
+			return nil, fmt.Errorf("%w: kid=%s", err, key.ID)
+		}
+		keySet.keys[key.ID] = key
+		if keySet.signingKeyID == "" && key.PrivateKey != nil {
+			keySet.signingKeyID = key.ID
+		}
+	}
+	if config.SigningKeyID != "" {
+		if key, ok := keySet.keys[config.SigningKeyID]; !ok || key.PrivateKey == nil {
+			return nil, fmt.Errorf("%w: kid=%s", ErrJWTKeyNotFound, config.SigningKeyID)
+		}
+		keySet.signingKeyID = config.SigningKeyID
+	}
+	return keySet, nil
+}
+
+// signingKey returns the key used to sign new tokens
+func (keySet *jwtKeySet) signingKey() (JWTKey, error) {
+	keySet.mutex.RLock()
+	defer keySet.mutex.RUnlock()
+	key, ok := keySet.keys[keySet.signingKeyID]
+	if !ok || key.PrivateKey == nil {
+		return JWTKey{}, ErrJWTKeyNotFound
+	}
+	return key, nil
+}
+
+// get returns the key with the given ID
+func (keySet *jwtKeySet) get(id string) (JWTKey, bool) {
+	keySet.mutex.RLock()
+	defer keySet.mutex.RUnlock()
+	key, ok := keySet.keys[id]
+	return key, ok
+}
+
+// verifyKey returns the key used to verify tokens
+func (key JWTKey) verifyKey() crypto.PublicKey {
+	if key.PublicKey != nil {
+		return key.PublicKey
+	}
+	switch k := key.PrivateKey.(type) {
+	case crypto.Signer:
+		return k.Public()
+	default:
+		return k
+	}
+}
+
+// validate checks that the key types match the signing method
+func (key JWTKey) validate() error {
+	if key.Method == nil || (key.PrivateKey == nil && key.PublicKey == nil) {
+		return ErrJWTKeyInvalid
+	}
+	var ok bool
+	switch key.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		_, ok = key.verifyKey().([]byte)
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		_, ok = key.verifyKey().(*rsa.PublicKey)
+	case *jwt.SigningMethodECDSA:
+		_, ok = key.verifyKey().(*ecdsa.PublicKey)
+	case *jwt.SigningMethodEd25519:
+		_, ok = key.verifyKey().(ed25519.PublicKey)
+	}
+	if !ok {
+		return ErrJWTKeyInvalid
+	}
+	return nil
+}
+
+// jwk returns the public JSON Web Key, or false for symmetric keys
+func (key JWTKey) jwk() (JWK, bool) {
+	jwk := JWK{
+		KeyID:     key.ID,
+		Use:       "sig",
+		Algorithm: key.Method.Alg(),
+	}
+	switch k := key.verifyKey().(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(k.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		jwk.KeyType = "EC"
+		jwk.Curve = k.Curve.Params().Name
+		jwk.X = base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(k)
+	default:
+		return JWK{}, false
+	}
+	return jwk, true
+}
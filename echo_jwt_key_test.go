@@ -0,0 +1,236 @@
+package utils_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+var (
+	testRSAKey, _        = rsa.GenerateKey(rand.Reader, 2048)
+	testECKey, _         = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, testEd25519Key, _ = ed25519.GenerateKey(rand.Reader)
+)
+
+func encodeTestPEM(t *testing.T, blockType string, key any) []byte {
+	var b []byte
+	var err error
+	switch blockType {
+	case "PRIVATE KEY":
+		b, err = x509.MarshalPKCS8PrivateKey(key)
+	case "PUBLIC KEY":
+		b, err = x509.MarshalPKIXPublicKey(key)
+	case "RSA PRIVATE KEY":
+		b = x509.MarshalPKCS1PrivateKey(key.(*rsa.PrivateKey))
+	case "EC PRIVATE KEY":
+		b, err = x509.MarshalECPrivateKey(key.(*ecdsa.PrivateKey))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: b})
+}
+
+func TestEchoJWTUtil_ParseKeyPEM(t *testing.T) {
+	tests := []struct {
+		name        string
+		alg         string
+		data        []byte
+		private     bool
+		expectedErr error
+	}{
+		{name: "RSA PKCS1 private key", alg: "RS256", data: encodeTestPEM(t, "RSA PRIVATE KEY", testRSAKey), private: true},
+		{name: "RSA PKCS8 private key", alg: "RS256", data: encodeTestPEM(t, "PRIVATE KEY", testRSAKey), private: true},
+		{name: "RSA public key", alg: "RS256", data: encodeTestPEM(t, "PUBLIC KEY", &testRSAKey.PublicKey)},
+		{name: "EC private key", alg: "ES256", data: encodeTestPEM(t, "EC PRIVATE KEY", testECKey), private: true},
+		{name: "EC public key", alg: "ES256", data: encodeTestPEM(t, "PUBLIC KEY", &testECKey.PublicKey)},
+		{name: "Ed25519 private key", alg: "EdDSA", data: encodeTestPEM(t, "PRIVATE KEY", testEd25519Key), private: true},
+		{name: "Ed25519 public key", alg: "EdDSA", data: encodeTestPEM(t, "PUBLIC KEY", testEd25519Key.Public())},
+		{name: "key does not match method", alg: "ES256", data: encodeTestPEM(t, "PUBLIC KEY", &testRSAKey.PublicKey), expectedErr: utils.ErrJWTKeyInvalid},
+		{name: "invalid pem", alg: "RS256", data: []byte("invalid"), expectedErr: utils.ErrJWTKeyPEM},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, err := utils.EchoJWT.ParseKeyPEM("key-1", test.alg, test.data)
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "key-1", key.ID)
+			assert.Equal(t, test.alg, key.Method.Alg())
+			assert.Equal(t, test.private, key.PrivateKey != nil)
+		})
+	}
+
+	t.Run("unknown method", func(t *testing.T) {
+		_, err := utils.EchoJWT.ParseKeyPEM("key-1", "XX256", nil)
+		assert.EqualError(t, err, "unexpected jwt signing method=XX256")
+	})
+}
+
+func TestEchoJWTUtil_AsymmetricKeys(t *testing.T) {
+	keys := []utils.JWTKey{
+		{ID: "rsa", Method: jwt.SigningMethodRS256, PrivateKey: testRSAKey},
+		{ID: "ec", Method: jwt.SigningMethodES256, PrivateKey: testECKey},
+		{ID: "ed", Method: jwt.SigningMethodEdDSA, PrivateKey: testEd25519Key},
+	}
+	for _, key := range keys {
+		t.Run(key.Method.Alg(), func(t *testing.T) {
+			echoJWT := utils.EchoJWT.New(&utils.EchoJWTConfig{
+				Keys:       keys,
+				ExpiresTTL: time.Hour,
+			})
+			assert.NoError(t, echoJWT.SetSigningKey(key.ID))
+			token := echoJWT.CreateToken(testClaims)
+			result, err := echoJWT.ParseToken(token.SignedString)
+			assert.NoError(t, err)
+			assert.Equal(t, key.ID, result.Header["kid"])
+			assert.Equal(t, key.Method.Alg(), result.Method.Alg())
+		})
+	}
+}
+
+func TestEchoJWTUtil_KeyRotation(t *testing.T) {
+	echoJWT := utils.EchoJWT.New(&utils.EchoJWTConfig{
+		SigningKey: testEchoJWTConfig.SigningKey,
+		Keys: []utils.JWTKey{
+			{ID: "key-1", Method: jwt.SigningMethodRS256, PrivateKey: testRSAKey},
+		},
+		ExpiresTTL: time.Hour,
+	})
+	legacyToken := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims)
+	legacySignedToken, _ := legacyToken.SignedString([]byte(testEchoJWTConfig.SigningKey))
+	oldToken := echoJWT.CreateToken(testClaims)
+
+	assert.NoError(t, echoJWT.AddKey(utils.JWTKey{ID: "key-2", Method: jwt.SigningMethodES256, PrivateKey: testECKey}))
+	assert.NoError(t, echoJWT.SetSigningKey("key-2"))
+	newToken := echoJWT.CreateToken(testClaims)
+
+	for _, signedToken := range []string{legacySignedToken, oldToken.SignedString, newToken.SignedString} {
+		_, err := echoJWT.ParseToken(signedToken)
+		assert.NoError(t, err)
+	}
+	parsed, _ := echoJWT.ParseToken(newToken.SignedString)
+	assert.Equal(t, "key-2", parsed.Header["kid"])
+
+	assert.ErrorIs(t, echoJWT.RemoveKey("key-2"), utils.ErrJWTKeyInUse)
+	assert.ErrorIs(t, echoJWT.RemoveKey("key-4"), utils.ErrJWTKeyNotFound)
+	assert.NoError(t, echoJWT.RemoveKey("key-1"))
+	_, err := echoJWT.ParseToken(oldToken.SignedString)
+	assert.EqualError(t, err, "unexpected jwt key id=key-1")
+
+	assert.ErrorIs(t, echoJWT.SetSigningKey("key-3"), utils.ErrJWTKeyNotFound)
+	assert.ErrorIs(t, echoJWT.AddKey(utils.JWTKey{ID: "key-3"}), utils.ErrJWTKeyInvalid)
+}
+
+func TestEchoJWTUtil_New_InvalidKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		config utils.EchoJWTConfig
+		err    error
+	}{
+		{
+			name:   "no key material",
+			config: utils.EchoJWTConfig{Keys: []utils.JWTKey{{ID: "key-1", Method: jwt.SigningMethodRS256}}},
+			err:    utils.ErrJWTKeyInvalid,
+		},
+		{
+			name:   "mismatched algorithm",
+			config: utils.EchoJWTConfig{Keys: []utils.JWTKey{{ID: "key-1", Method: jwt.SigningMethodES256, PrivateKey: testRSAKey}}},
+			err:    utils.ErrJWTKeyInvalid,
+		},
+		{
+			name:   "unknown signing key",
+			config: utils.EchoJWTConfig{SigningKey: "secret", SigningKeyID: "key-1"},
+			err:    utils.ErrJWTKeyNotFound,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			echoJWT, err := utils.EchoJWT.NewWithKeys(&test.config)
+			assert.Nil(t, echoJWT)
+			assert.ErrorIs(t, err, test.err)
+			_, err = utils.NewEchoJWTClaimsWithKeys[testCustomClaims](&test.config)
+			assert.ErrorIs(t, err, test.err)
+
+			echoJWT = utils.EchoJWT.New(&test.config)
+			_, err = echoJWT.ParseToken(utils.EchoJWT.New(testEchoJWTConfig).CreateToken(testClaims).SignedString)
+			assert.ErrorIs(t, err, test.err)
+			assert.ErrorIs(t, echoJWT.AddKey(utils.JWTKey{ID: "key-2", Method: jwt.SigningMethodRS256, PrivateKey: testRSAKey}), test.err)
+		})
+	}
+}
+
+func TestEchoJWTUtil_NotCreatedWithNew(t *testing.T) {
+	echoJWT := utils.EchoJWTUtil{Config: testEchoJWTConfig}
+	token := echoJWT.CreateToken(testClaims)
+	_, err := echoJWT.ParseToken(token.SignedString)
+	assert.NoError(t, err)
+	key := utils.JWTKey{ID: "key-1", Method: jwt.SigningMethodRS256, PrivateKey: testRSAKey}
+	assert.ErrorIs(t, echoJWT.AddKey(key), utils.ErrJWTKeySetNotCreated)
+	assert.ErrorIs(t, echoJWT.SetSigningKey(""), utils.ErrJWTKeySetNotCreated)
+	assert.ErrorIs(t, echoJWT.RemoveKey(""), utils.ErrJWTKeySetNotCreated)
+}
+
+func TestEchoJWTUtil_JWKS(t *testing.T) {
+	echoJWT := utils.EchoJWT.New(&utils.EchoJWTConfig{
+		SigningKey: testEchoJWTConfig.SigningKey,
+		Keys: []utils.JWTKey{
+			{ID: "rsa", Method: jwt.SigningMethodRS256, PrivateKey: testRSAKey},
+			{ID: "ec", Method: jwt.SigningMethodES256, PublicKey: &testECKey.PublicKey},
+			{ID: "ed", Method: jwt.SigningMethodEdDSA, PrivateKey: testEd25519Key},
+			{ID: "hmac", Method: jwt.SigningMethodHS512, PrivateKey: []byte("secret")},
+		},
+	})
+	e := echo.New()
+	e.GET(utils.JWKSPath, echoJWT.JWKSHandler)
+	req := httptest.NewRequest(http.MethodGet, utils.JWKSPath, nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	jwks := utils.JWKSet{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &jwks))
+	assert.Len(t, jwks.Keys, 3)
+	decode := func(s string) []byte {
+		b, _ := base64.RawURLEncoding.DecodeString(s)
+		return b
+	}
+
+	ec := jwks.Keys[0]
+	assert.Equal(t, "ec", ec.KeyID)
+	assert.Equal(t, "EC", ec.KeyType)
+	assert.Equal(t, "P-256", ec.Curve)
+	assert.Equal(t, "ES256", ec.Algorithm)
+	assert.Equal(t, testECKey.X, new(big.Int).SetBytes(decode(ec.X)))
+	assert.Equal(t, testECKey.Y, new(big.Int).SetBytes(decode(ec.Y)))
+
+	ed := jwks.Keys[1]
+	assert.Equal(t, "OKP", ed.KeyType)
+	assert.Equal(t, "Ed25519", ed.Curve)
+	assert.Equal(t, []byte(testEd25519Key.Public().(ed25519.PublicKey)), decode(ed.X))
+
+	rsaKey := jwks.Keys[2]
+	assert.Equal(t, "RSA", rsaKey.KeyType)
+	assert.Equal(t, "sig", rsaKey.Use)
+	assert.Equal(t, testRSAKey.N, new(big.Int).SetBytes(decode(rsaKey.N)))
+	assert.Equal(t, int64(testRSAKey.E), new(big.Int).SetBytes(decode(rsaKey.E)).Int64())
+}
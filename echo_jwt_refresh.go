@@ -0,0 +1,318 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+)
+
+// Default values for JWTRefreshConfig
+const (
+	DefaultRefreshTokenKeyPrefix = "refresh_token"
+	DefaultRefreshTokenTTL       = 30 * 24 * time.Hour
+	DefaultRefreshTokenPath      = "/token/refresh"
+	DefaultRefreshTokenGrace     = 10 * time.Second
+	DefaultRefreshTokenMaxAge    = 90 * 24 * time.Hour
+)
+
+// Define limits of refresh tokens.
+const (
+	refreshTokenSecretSize   = 32 // Number of random bytes in a refresh token secret
+	refreshRedisMaxTxRetries = 3  // Max retries of refreshes of a family updated concurrently
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("invalid refresh token")        // Error for unknown, expired or malformed refresh tokens.
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected") // Error for refresh tokens that were already rotated.
+)
+
+// JWTRefreshConfig is the configuration for JWTRefreshHandler
+type JWTRefreshConfig struct {
+	Client    *RedisClient  // Redis client instance used to store refresh token families
+	KeyPrefix string        // Prefix for the Redis keys, defaults to DefaultRefreshTokenKeyPrefix
+	TTL       time.Duration // How long a refresh token is valid, defaults to DefaultRefreshTokenTTL
+	Grace     time.Duration // How long the previous refresh token returns the pair of its rotation again, e.g. for retries, defaults to DefaultRefreshTokenGrace
+	MaxAge    time.Duration // The maximum lifetime of a family regardless of refreshes, defaults to DefaultRefreshTokenMaxAge
+}
+
+// JWTTokenPair is an access token together with its refresh token
+type JWTTokenPair struct {
+	AccessToken  string `json:"accessToken"`  // The signed access token
+	RefreshToken string `json:"refreshToken"` // The opaque refresh token
+	TokenType    string `json:"tokenType"`    // The token type, always Bearer
+	ExpiresIn    int64  `json:"expiresIn"`    // Seconds until the access token expires
+}
+
+// JWTRefreshRequest is the request body of the refresh endpoint
+type JWTRefreshRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+// JWTRefreshHandler issues access and refresh token pairs. Refresh tokens of one login
+// form a family stored in Redis; every refresh rotates the refresh token, and presenting
+// a refresh token that was already rotated revokes the whole family, except for the
+// previous refresh token within the grace period. Families expire after MaxAge and
+// are revoked with their subject by the RevocationHandler of the util.
+type JWTRefreshHandler struct {
+	echoJWT   EchoJWTUtil
+	client    *RedisClient
+	keyPrefix string
+	ttl       time.Duration
+	grace     time.Duration
+	maxAge    time.Duration
+}
+
+// jwtRefreshFamily is the state of a refresh token family stored in Redis
+type jwtRefreshFamily struct {
+	TokenHash         string          `json:"tokenHash"`         // SHA256 hash of the current refresh token secret
+	PreviousTokenHash string          `json:"previousTokenHash"` // SHA256 hash of the previous refresh token secret
+	RotatedAt         time.Time       `json:"rotatedAt"`         // When the previous refresh token was rotated
+	CreatedAt         time.Time       `json:"createdAt"`         // When the family was issued
+	RotatedPair       string          `json:"rotatedPair"`       // The pair issued by the last rotation, encrypted with the previous refresh token secret
+	Claims            json.RawMessage `json:"claims"`            // Claims of the access tokens issued for the family
+}
+
+// NewRefreshHandler creates a new refresh token handler using the provided configuration
+func (eJWT EchoJWTUtil) NewRefreshHandler(config JWTRefreshConfig) *JWTRefreshHandler {
+	if config.KeyPrefix = strings.TrimSpace(config.KeyPrefix); config.KeyPrefix == "" {
+		config.KeyPrefix = DefaultRefreshTokenKeyPrefix
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultRefreshTokenTTL
+	}
+	if config.Grace <= 0 {
+		config.Grace = DefaultRefreshTokenGrace
+	}
+	if config.MaxAge <= 0 {
+		config.MaxAge = DefaultRefreshTokenMaxAge
+	}
+	return &JWTRefreshHandler{
+		echoJWT:   eJWT,
+		client:    config.Client,
+		keyPrefix: config.KeyPrefix,
+		ttl:       config.TTL,
+		grace:     config.Grace,
+		maxAge:    config.MaxAge,
+	}
+}
+
+// IssueTokenPair creates an access token for the claims and starts a new refresh token family.
+// The claims must be *jwt.RegisteredClaims or a pointer to the custom claims of the util.
+func (h *JWTRefreshHandler) IssueTokenPair(claims jwt.Claims) (JWTTokenPair, error) {
+	familyID := String.UUID()
+	family := jwtRefreshFamily{CreatedAt: time.Now()}
+	pair, err := h.rotate(familyID, &family, claims)
+	if err != nil {
+		return JWTTokenPair{}, err
+	}
+	if err := h.client.SetStruct(h.key(familyID), family, h.familyTTL(family)); err != nil {
+		return JWTTokenPair{}, err
+	}
+	return pair, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair, rotating the refresh token.
+// If the refresh token was already rotated, the family is revoked and ErrRefreshTokenReused is returned,
+// unless it is the previous refresh token presented within the grace period, e.g. by a retry, which
+// returns the pair issued by the rotation again. Families of revoked subjects are revoked with ErrJWTRevoked.
+func (h *JWTRefreshHandler) Refresh(refreshToken string) (JWTTokenPair, error) {
+	familyID, secret, ok := strings.Cut(refreshToken, ".")
+	if !ok || familyID == "" || secret == "" {
+		return JWTTokenPair{}, ErrRefreshTokenInvalid
+	}
+	ctx := context.TODO()
+	key := h.key(familyID)
+	var pair JWTTokenPair
+	refresh := func(tx *redis.Tx) error {
+		val, err := tx.Get(ctx, key).Result()
+		if err == redis.Nil {
+			return ErrRefreshTokenInvalid
+		}
+		if err != nil {
+			return err
+		}
+		family := jwtRefreshFamily{}
+		if err := json.Unmarshal([]byte(val), &family); err != nil {
+			return err
+		}
+		if time.Since(family.CreatedAt) >= h.maxAge {
+			if err := tx.Del(ctx, key).Err(); err != nil {
+				return err
+			}
+			return ErrRefreshTokenInvalid
+		}
+		claims := h.echoJWT.claims()
+		if err := json.Unmarshal(family.Claims, claims); err != nil {
+			return err
+		}
+		if err := h.checkRevocation(claims); err != nil {
+			if delErr := tx.Del(ctx, key).Err(); delErr != nil {
+				return delErr
+			}
+			return err
+		}
+		secretHash := []byte(String.SHA256(secret))
+		if subtle.ConstantTimeCompare(secretHash, []byte(family.PreviousTokenHash)) == 1 &&
+			time.Since(family.RotatedAt) <= h.grace {
+			pair, err = h.rotatedPair(family, secret)
+			return err
+		}
+		if subtle.ConstantTimeCompare(secretHash, []byte(family.TokenHash)) != 1 {
+			if err := tx.Del(ctx, key).Err(); err != nil {
+				return err
+			}
+			return ErrRefreshTokenReused
+		}
+		if registered := registeredClaimsOf(claims); registered != nil {
+			registered.ID = ""
+			registered.IssuedAt = nil
+			registered.ExpiresAt = nil
+		}
+		family.PreviousTokenHash = family.TokenHash
+		family.RotatedAt = time.Now()
+		if pair, err = h.rotate(familyID, &family, claims); err != nil {
+			return err
+		}
+		if family.RotatedPair, err = h.encryptPair(pair, secret); err != nil {
+			return err
+		}
+		b, _ := json.Marshal(family)
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return pipe.Set(ctx, key, string(b), h.familyTTL(family)).Err()
+		})
+		return err
+	}
+	for i := 0; i < refreshRedisMaxTxRetries; i++ {
+		err := h.client.Watch(ctx, refresh, key)
+		if err == redis.TxFailedErr {
+			continue
+		}
+		if err != nil {
+			return JWTTokenPair{}, err
+		}
+		return pair, nil
+	}
+	return JWTTokenPair{}, ErrRefreshTokenInvalid
+}
+
+// Revoke revokes the family of the given refresh token, e.g. on logout
+func (h *JWTRefreshHandler) Revoke(refreshToken string) error {
+	familyID, _, ok := strings.Cut(refreshToken, ".")
+	if !ok || familyID == "" {
+		return ErrRefreshTokenInvalid
+	}
+	return h.client.Del(context.TODO(), h.key(familyID)).Err()
+}
+
+// RefreshHandler handles requests to the DefaultRefreshTokenPath endpoint,
+// exchanging the refresh token of a JWTRefreshRequest for a new JWTTokenPair
+func (h *JWTRefreshHandler) RefreshHandler(c echo.Context) error {
+	req := JWTRefreshRequest{}
+	if err := c.Bind(&req); err != nil {
+		return NewCommonErrorBadRequest(err)
+	}
+	if err := ValidateStruct(req); err != nil {
+		return NewCommonErrorBadRequest(err)
+	}
+	pair, err := h.Refresh(req.RefreshToken)
+	if errors.Is(err, ErrRefreshTokenInvalid) || errors.Is(err, ErrRefreshTokenReused) || errors.Is(err, ErrJWTRevoked) {
+		return NewCommonErrorUnauthorized(err)
+	}
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, pair)
+}
+
+// rotate signs a new access token for the claims and generates a new refresh token secret,
+// updating the family with the claims and the hash of the secret
+func (h *JWTRefreshHandler) rotate(familyID string, family *jwtRefreshFamily, claims jwt.Claims) (JWTTokenPair, error) {
+	registered := registeredClaimsOf(claims)
+	if registered == nil {
+		return JWTTokenPair{}, ErrJWTClaimsNotRegistered
+	}
+	h.echoJWT.setDefaultClaims(registered)
+	accessToken, err := h.echoJWT.signToken(claims)
+	if err != nil {
+		return JWTTokenPair{}, err
+	}
+	secret := make([]byte, refreshTokenSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return JWTTokenPair{}, err
+	}
+	refreshSecret := base64.RawURLEncoding.EncodeToString(secret)
+	if family.Claims, err = json.Marshal(claims); err != nil {
+		return JWTTokenPair{}, err
+	}
+	family.TokenHash = String.SHA256(refreshSecret)
+	return JWTTokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: familyID + "." + refreshSecret,
+		TokenType:    "Bearer",
+		ExpiresIn:    registered.ExpiresAt.Unix() - time.Now().Unix(),
+	}, nil
+}
+
+// checkRevocation returns ErrJWTRevoked if the claims of the family are revoked by the RevocationHandler
+func (h *JWTRefreshHandler) checkRevocation(claims jwt.Claims) error {
+	registered := registeredClaimsOf(claims)
+	if h.echoJWT.Config.RevocationHandler == nil || registered == nil {
+		return nil
+	}
+	revoked, err := h.echoJWT.Config.RevocationHandler.IsRevoked(registered)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return ErrJWTRevoked
+	}
+	return nil
+}
+
+// encryptPair encrypts the pair issued by a rotation with the rotated refresh token secret,
+// so only the holder of the previous refresh token can retrieve it during the grace period
+func (h *JWTRefreshHandler) encryptPair(pair JWTTokenPair, secret string) (string, error) {
+	key, err := base64.RawURLEncoding.DecodeString(secret)
+	if err != nil {
+		return "", err
+	}
+	b, _ := json.Marshal(pair)
+	return String.AESEncrypt(string(key), string(b))
+}
+
+// rotatedPair decrypts the pair issued by the last rotation using the previous refresh token secret
+func (h *JWTRefreshHandler) rotatedPair(family jwtRefreshFamily, secret string) (JWTTokenPair, error) {
+	key, err := base64.RawURLEncoding.DecodeString(secret)
+	if err != nil {
+		return JWTTokenPair{}, ErrRefreshTokenInvalid
+	}
+	plaintext, err := String.AESDecrypt(string(key), family.RotatedPair)
+	if err != nil {
+		return JWTTokenPair{}, ErrRefreshTokenInvalid
+	}
+	pair := JWTTokenPair{}
+	if err := json.Unmarshal([]byte(plaintext), &pair); err != nil {
+		return JWTTokenPair{}, err
+	}
+	return pair, nil
+}
+
+// familyTTL returns how long a family is kept, at most until its MaxAge
+func (h *JWTRefreshHandler) familyTTL(family jwtRefreshFamily) time.Duration {
+	return Min(h.ttl, time.Until(family.CreatedAt.Add(h.maxAge)))
+}
+
+// key returns the Redis key of a refresh token family
+func (h *JWTRefreshHandler) key(familyID string) string {
+	return h.keyPrefix + ":" + familyID
+}
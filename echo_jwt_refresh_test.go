@@ -0,0 +1,185 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+func newTestRefreshHandler(t *testing.T, echoJWT *utils.EchoJWTUtil, config utils.JWTRefreshConfig) *utils.JWTRefreshHandler {
+	_, client := newTestRedisClient(t)
+	config.Client = client
+	if config.TTL == 0 {
+		config.TTL = time.Hour
+	}
+	return echoJWT.NewRefreshHandler(config)
+}
+
+func TestJWTRefreshHandler_Refresh(t *testing.T) {
+	echoJWT := utils.EchoJWT.New(testEchoJWTConfig)
+
+	t.Run("rotation", func(t *testing.T) {
+		handler := newTestRefreshHandler(t, echoJWT, utils.JWTRefreshConfig{})
+		pair, err := handler.IssueTokenPair(&jwt.RegisteredClaims{Subject: "test-user"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Bearer", pair.TokenType)
+		assert.InDelta(t, time.Hour.Seconds(), pair.ExpiresIn, 1)
+
+		refreshed, err := handler.Refresh(pair.RefreshToken)
+		assert.NoError(t, err)
+		assert.NotEqual(t, pair.RefreshToken, refreshed.RefreshToken)
+		assert.NotEqual(t, pair.AccessToken, refreshed.AccessToken)
+		oldToken, _ := echoJWT.ParseToken(pair.AccessToken)
+		newToken, err := echoJWT.ParseToken(refreshed.AccessToken)
+		assert.NoError(t, err)
+		oldClaims, _ := echoJWT.GetClaims(oldToken)
+		newClaims, _ := echoJWT.GetClaims(newToken)
+		assert.Equal(t, "test-user", newClaims.Subject)
+		assert.NotEqual(t, oldClaims.ID, newClaims.ID)
+
+		_, err = handler.Refresh(refreshed.RefreshToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("retry within grace", func(t *testing.T) {
+		handler := newTestRefreshHandler(t, echoJWT, utils.JWTRefreshConfig{})
+		pair, _ := handler.IssueTokenPair(&jwt.RegisteredClaims{Subject: "test-user"})
+		var wg sync.WaitGroup
+		pairs := make([]utils.JWTTokenPair, 2)
+		errs := make([]error, 2)
+		for i := range pairs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				pairs[i], errs[i] = handler.Refresh(pair.RefreshToken)
+			}(i)
+		}
+		wg.Wait()
+		assert.NoError(t, errs[0])
+		assert.NoError(t, errs[1])
+		assert.Equal(t, pairs[0], pairs[1])
+
+		retried, err := handler.Refresh(pair.RefreshToken)
+		assert.NoError(t, err)
+		assert.Equal(t, pairs[0], retried)
+		_, err = handler.Refresh(retried.RefreshToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("revoked subject", func(t *testing.T) {
+		config := *testEchoJWTConfig
+		config.RevocationHandler = newTestRevocationHandler(t)
+		handler := newTestRefreshHandler(t, utils.EchoJWT.New(&config), utils.JWTRefreshConfig{})
+		pair, _ := handler.IssueTokenPair(&jwt.RegisteredClaims{Subject: "test-user"})
+		assert.NoError(t, config.RevocationHandler.RevokeSubject("test-user", time.Now().Add(time.Second)))
+		_, err := handler.Refresh(pair.RefreshToken)
+		assert.ErrorIs(t, err, utils.ErrJWTRevoked)
+		_, err = handler.Refresh(pair.RefreshToken)
+		assert.ErrorIs(t, err, utils.ErrRefreshTokenInvalid)
+	})
+
+	t.Run("reuse revokes family", func(t *testing.T) {
+		handler := newTestRefreshHandler(t, echoJWT, utils.JWTRefreshConfig{Grace: time.Millisecond})
+		pair, _ := handler.IssueTokenPair(&jwt.RegisteredClaims{Subject: "test-user"})
+		refreshed, _ := handler.Refresh(pair.RefreshToken)
+		time.Sleep(5 * time.Millisecond)
+		_, err := handler.Refresh(pair.RefreshToken)
+		assert.ErrorIs(t, err, utils.ErrRefreshTokenReused)
+		_, err = handler.Refresh(refreshed.RefreshToken)
+		assert.ErrorIs(t, err, utils.ErrRefreshTokenInvalid)
+	})
+
+	t.Run("max age", func(t *testing.T) {
+		handler := newTestRefreshHandler(t, echoJWT, utils.JWTRefreshConfig{MaxAge: 50 * time.Millisecond})
+		pair, _ := handler.IssueTokenPair(&jwt.RegisteredClaims{Subject: "test-user"})
+		refreshed, err := handler.Refresh(pair.RefreshToken)
+		assert.NoError(t, err)
+		time.Sleep(60 * time.Millisecond)
+		_, err = handler.Refresh(refreshed.RefreshToken)
+		assert.ErrorIs(t, err, utils.ErrRefreshTokenInvalid)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		handler := newTestRefreshHandler(t, echoJWT, utils.JWTRefreshConfig{})
+		pair, _ := handler.IssueTokenPair(&jwt.RegisteredClaims{Subject: "test-user"})
+		assert.NoError(t, handler.Revoke(pair.RefreshToken))
+		_, err := handler.Refresh(pair.RefreshToken)
+		assert.ErrorIs(t, err, utils.ErrRefreshTokenInvalid)
+		assert.ErrorIs(t, handler.Revoke("invalid"), utils.ErrRefreshTokenInvalid)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		handler := newTestRefreshHandler(t, echoJWT, utils.JWTRefreshConfig{})
+		for _, token := range []string{"", "invalid", "family.", "unknown.secret"} {
+			_, err := handler.Refresh(token)
+			assert.ErrorIs(t, err, utils.ErrRefreshTokenInvalid, token)
+		}
+	})
+}
+
+func TestJWTRefreshHandler_CustomClaims(t *testing.T) {
+	echoJWT := utils.NewEchoJWTClaims[testCustomClaims](testEchoJWTConfig)
+	handler := newTestRefreshHandler(t, echoJWT.EchoJWTUtil, utils.JWTRefreshConfig{})
+	pair, err := handler.IssueTokenPair(&testCustomClaims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "test-user"},
+		TenantID:         "tenant-1",
+	})
+	assert.NoError(t, err)
+	refreshed, err := handler.Refresh(pair.RefreshToken)
+	assert.NoError(t, err)
+	token, err := echoJWT.ParseToken(refreshed.AccessToken)
+	assert.NoError(t, err)
+	claims, _ := echoJWT.GetClaims(token)
+	assert.Equal(t, "tenant-1", claims.TenantID)
+
+	_, err = handler.IssueTokenPair(jwt.MapClaims{})
+	assert.ErrorIs(t, err, utils.ErrJWTClaimsNotRegistered)
+}
+
+func TestJWTRefreshHandler_RefreshHandler(t *testing.T) {
+	echoJWT := utils.EchoJWT.New(testEchoJWTConfig)
+	handler := newTestRefreshHandler(t, echoJWT, utils.JWTRefreshConfig{})
+	e := echo.New()
+	e.POST(utils.DefaultRefreshTokenPath, handler.RefreshHandler)
+	pair, _ := handler.IssueTokenPair(&jwt.RegisteredClaims{Subject: "test-user"})
+	serve := func(body string) (*httptest.ResponseRecorder, error) {
+		var handlerErr error
+		e.HTTPErrorHandler = func(err error, c echo.Context) {
+			handlerErr = err
+		}
+		req := httptest.NewRequest(http.MethodPost, utils.DefaultRefreshTokenPath, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec, handlerErr
+	}
+
+	rec, err := serve(`{"refreshToken":"` + pair.RefreshToken + `"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	refreshed := utils.JWTTokenPair{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &refreshed))
+	assert.NotEmpty(t, refreshed.AccessToken)
+
+	familyID, _, _ := strings.Cut(pair.RefreshToken, ".")
+	_, err = serve(`{"refreshToken":"` + familyID + `.unknown"}`)
+	resp := utils.ParseErrorResponse(err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, utils.ErrCodeUnauthorized, resp.ErrorCode)
+	assert.Equal(t, utils.ErrRefreshTokenReused.Error(), resp.ErrorMessage)
+
+	_, err = serve(`{}`)
+	resp = utils.ParseErrorResponse(err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "Validation failed for 'refreshToken'", resp.ErrorMessage)
+}
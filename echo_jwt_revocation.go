@@ -0,0 +1,220 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/redis/go-redis/v9"
+)
+
+// Default values for JWTRevocationRedisConfig
+const (
+	DefaultRedisRevocationKey = "jwt_revocation"
+	DefaultJWTRevocationTTL   = 24 * time.Hour
+)
+
+// Define limits for Redis access of revocations.
+const (
+	revocationRedisLimitGetTx   = 100 // Max Redis keys fetched per call when listing revocations
+	revocationRedisMaxTxRetries = 3   // Max retries of subject revocations updated concurrently
+)
+
+var (
+	ErrJWTRevoked        = errors.New("token has been revoked") // Error for revoked tokens parsed by the middleware.
+	ErrJWTRevocationNoID = errors.New("token has no id")        // Error for revoking a token without an ID.
+)
+
+// JWTRevocationRedisConfig is used to configure token revocations stored in Redis.
+type JWTRevocationRedisConfig struct {
+	KeyPrefix   string        // Prefix for the Redis keys, defaults to DefaultRedisRevocationKey
+	MaxTokenTTL time.Duration // Longest token lifetime, used to expire subject revocations, defaults to DefaultJWTRevocationTTL
+	Client      *RedisClient  // Redis client instance to use for accessing the server
+}
+
+// JWTRevocation represents a revoked token, or all tokens of a subject issued before a time.
+type JWTRevocation struct {
+	ID           string `json:"jti,omitempty"`          // ID of the revoked token.
+	Subject      string `json:"sub,omitempty"`          // Subject of the revoked token(s).
+	IssuedBefore int64  `json:"issuedBefore,omitempty"` // Unix time before which tokens of the subject were issued.
+	RevokedAt    int64  `json:"revokedAt"`              // Unix time of the revocation.
+}
+
+// JWTRevocationHandler represents an interface for managing revoked tokens.
+type JWTRevocationHandler interface {
+	Revoke(claims *jwt.RegisteredClaims) error
+	RevokeSubject(subject string, issuedBefore time.Time) error
+	IsRevoked(claims *jwt.RegisteredClaims) (bool, error)
+	List() ([]JWTRevocation, error)
+}
+
+// JWTRevocationRedisHandler is used to handle token revocations stored in Redis.
+type JWTRevocationRedisHandler struct {
+	prefixKey   string
+	maxTokenTTL time.Duration
+	client      *RedisClient
+}
+
+// NewJWTRevocationHandler creates a new Redis token revocation handler using the provided configuration.
+func (RedisUtil) NewJWTRevocationHandler(config JWTRevocationRedisConfig) JWTRevocationHandler {
+	config.KeyPrefix = strings.TrimSpace(config.KeyPrefix)
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = DefaultRedisRevocationKey
+	}
+	if config.MaxTokenTTL <= 0 {
+		config.MaxTokenTTL = DefaultJWTRevocationTTL
+	}
+	return &JWTRevocationRedisHandler{
+		prefixKey:   config.KeyPrefix,
+		maxTokenTTL: config.MaxTokenTTL,
+		client:      config.Client,
+	}
+}
+
+// Revoke revokes a single token by its ID, until the token expires.
+func (h *JWTRevocationRedisHandler) Revoke(claims *jwt.RegisteredClaims) error {
+	if claims.ID == "" {
+		return ErrJWTRevocationNoID
+	}
+	ttl := h.maxTokenTTL
+	if claims.ExpiresAt != nil {
+		ttl = time.Duration(Max(1, claims.ExpiresAt.Unix()-time.Now().Unix())) * time.Second
+	}
+	revocation := JWTRevocation{
+		ID:        claims.ID,
+		Subject:   claims.Subject,
+		RevokedAt: time.Now().Unix(),
+	}
+	return h.client.SetStruct(h.idKey(claims.ID), revocation, ttl)
+}
+
+// RevokeSubject revokes all tokens of a subject issued before the given time, until they expire.
+// The time is rounded up to the next second, as token issue times have second precision, so that
+// tokens issued in the same second are revoked too. An existing revocation of the subject with a
+// later time is kept.
+func (h *JWTRevocationRedisHandler) RevokeSubject(subject string, issuedBefore time.Time) error {
+	ttl := time.Until(issuedBefore.Add(h.maxTokenTTL))
+	if ttl <= 0 {
+		return nil
+	}
+	issuedBeforeUnix := issuedBefore.Unix()
+	if issuedBefore.After(time.Unix(issuedBeforeUnix, 0)) {
+		issuedBeforeUnix++
+	}
+	ctx := context.TODO()
+	key := h.subjectKey(subject)
+	revocation := JWTRevocation{
+		Subject:      subject,
+		IssuedBefore: issuedBeforeUnix,
+		RevokedAt:    time.Now().Unix(),
+	}
+	b, _ := json.Marshal(revocation)
+	var err error
+	for i := 0; i < revocationRedisMaxTxRetries; i++ {
+		err = h.client.Watch(ctx, func(tx *redis.Tx) error {
+			val, err := tx.Get(ctx, key).Result()
+			if err != nil && err != redis.Nil {
+				return err
+			}
+			existing := JWTRevocation{}
+			if err == nil && json.Unmarshal([]byte(val), &existing) == nil && existing.IssuedBefore >= revocation.IssuedBefore {
+				return nil
+			}
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				return pipe.Set(ctx, key, string(b), ttl).Err()
+			})
+			return err
+		}, key)
+		if err != redis.TxFailedErr {
+			return err
+		}
+	}
+	return err
+}
+
+// IsRevoked checks if a token is revoked by its ID or by the revocation of its subject.
+func (h *JWTRevocationRedisHandler) IsRevoked(claims *jwt.RegisteredClaims) (bool, error) {
+	keys := []string{h.idKey(claims.ID), h.subjectKey(claims.Subject)}
+	values, err := h.client.MGet(context.TODO(), keys...).Result()
+	if err != nil {
+		return false, err
+	}
+	if values[0] != nil {
+		return true, nil
+	}
+	if s, ok := values[1].(string); ok {
+		revocation := JWTRevocation{}
+		if err := json.Unmarshal([]byte(s), &revocation); err != nil {
+			return false, err
+		}
+		return claims.IssuedAt == nil || claims.IssuedAt.Unix() < revocation.IssuedBefore, nil
+	}
+	return false, nil
+}
+
+// List returns all active revocations.
+func (h *JWTRevocationRedisHandler) List() ([]JWTRevocation, error) {
+	var cursor uint64
+	ctx := context.TODO()
+	revocations := []JWTRevocation{}
+	for {
+		var err error
+		var keys []string
+		keys, cursor, err = h.client.Scan(ctx, cursor, escapeRedisPattern(h.prefixKey)+":*", revocationRedisLimitGetTx).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(keys) > 0 {
+			values, err := h.client.MGet(ctx, keys...).Result()
+			if err != nil {
+				return nil, err
+			}
+			for _, v := range values {
+				s, ok := v.(string)
+				if !ok {
+					continue
+				}
+				revocation := JWTRevocation{}
+				if err := json.Unmarshal([]byte(s), &revocation); err == nil {
+					revocations = append(revocations, revocation)
+				}
+			}
+		}
+		if cursor == 0 {
+			break
+		}
+	}
+	return revocations, nil
+}
+
+// idKey returns the Redis key of a token revocation.
+func (h *JWTRevocationRedisHandler) idKey(id string) string {
+	return h.prefixKey + ":jti:" + id
+}
+
+// subjectKey returns the Redis key of a subject revocation.
+func (h *JWTRevocationRedisHandler) subjectKey(subject string) string {
+	return h.prefixKey + ":sub:" + subject
+}
+
+// checkRevocation returns ErrJWTRevoked if the token is revoked by the configured handler
+func (eJWT EchoJWTUtil) checkRevocation(token *jwt.Token) error {
+	if eJWT.Config.RevocationHandler == nil {
+		return nil
+	}
+	claims, err := eJWT.GetClaims(token)
+	if err != nil {
+		return err
+	}
+	revoked, err := eJWT.Config.RevocationHandler.IsRevoked(claims)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return ErrJWTRevoked
+	}
+	return nil
+}
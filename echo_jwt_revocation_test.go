@@ -0,0 +1,155 @@
+package utils_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+func newTestRevocationHandler(t *testing.T) utils.JWTRevocationHandler {
+	_, client := newTestRedisClient(t)
+	return utils.Redis.NewJWTRevocationHandler(utils.JWTRevocationRedisConfig{Client: client})
+}
+
+func TestJWTRevocationRedisHandler_Revoke(t *testing.T) {
+	handler := newTestRevocationHandler(t)
+	claims := &jwt.RegisteredClaims{
+		ID:        "token-1",
+		Subject:   "test-user",
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+	revoked, err := handler.IsRevoked(claims)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	assert.NoError(t, handler.Revoke(claims))
+	revoked, err = handler.IsRevoked(claims)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	other := *claims
+	other.ID = "token-2"
+	revoked, _ = handler.IsRevoked(&other)
+	assert.False(t, revoked)
+
+	assert.ErrorIs(t, handler.Revoke(&jwt.RegisteredClaims{}), utils.ErrJWTRevocationNoID)
+}
+
+func TestJWTRevocationRedisHandler_RevokeSubject(t *testing.T) {
+	handler := newTestRevocationHandler(t)
+	now := time.Now()
+	before := &jwt.RegisteredClaims{ID: "token-1", Subject: "test-user", IssuedAt: jwt.NewNumericDate(now.Add(-time.Minute))}
+	after := &jwt.RegisteredClaims{ID: "token-2", Subject: "test-user", IssuedAt: jwt.NewNumericDate(now.Add(time.Minute))}
+	other := &jwt.RegisteredClaims{ID: "token-3", Subject: "other-user", IssuedAt: jwt.NewNumericDate(now.Add(-time.Minute))}
+
+	assert.NoError(t, handler.RevokeSubject("test-user", now))
+	revoked, err := handler.IsRevoked(before)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, _ = handler.IsRevoked(after)
+	assert.False(t, revoked)
+	revoked, _ = handler.IsRevoked(other)
+	assert.False(t, revoked)
+}
+
+func TestJWTRevocationRedisHandler_RevokeSubject_IssuedBefore(t *testing.T) {
+	s, client := newTestRedisClient(t)
+	handler := utils.Redis.NewJWTRevocationHandler(utils.JWTRevocationRedisConfig{Client: client, MaxTokenTTL: time.Hour})
+	now := time.Now()
+	sameSecond := &jwt.RegisteredClaims{ID: "token-1", Subject: "test-user", IssuedAt: jwt.NewNumericDate(now)}
+	earlier := &jwt.RegisteredClaims{ID: "token-2", Subject: "test-user", IssuedAt: jwt.NewNumericDate(now.Add(-time.Minute))}
+	nextSecond := &jwt.RegisteredClaims{ID: "token-3", Subject: "test-user", IssuedAt: jwt.NewNumericDate(now.Truncate(time.Second).Add(time.Second))}
+
+	assert.NoError(t, handler.RevokeSubject("test-user", now))
+	revoked, _ := handler.IsRevoked(sameSecond)
+	assert.True(t, revoked)
+	revoked, _ = handler.IsRevoked(nextSecond)
+	assert.False(t, revoked)
+	revoked, _ = handler.IsRevoked(earlier)
+	assert.True(t, revoked)
+	ttl := s.TTL("jwt_revocation:sub:test-user")
+	assert.Greater(t, ttl, 59*time.Minute)
+	assert.LessOrEqual(t, ttl, time.Hour)
+
+	assert.NoError(t, handler.RevokeSubject("test-user", now.Add(-2*time.Minute)))
+	revoked, _ = handler.IsRevoked(earlier)
+	assert.True(t, revoked)
+
+	assert.NoError(t, handler.RevokeSubject("old-user", now.Add(-2*time.Hour)))
+	assert.False(t, s.Exists("jwt_revocation:sub:old-user"))
+}
+
+func TestJWTRevocationRedisHandler_RevokeSubject_ImmediatelyAfterIssue(t *testing.T) {
+	handler := newTestRevocationHandler(t)
+	echoJWT := utils.EchoJWT.New(testEchoJWTConfig)
+	token, err := echoJWT.ParseToken(echoJWT.CreateToken(jwt.RegisteredClaims{Subject: "test-user"}).SignedString)
+	assert.NoError(t, err)
+
+	assert.NoError(t, handler.RevokeSubject("test-user", time.Now()))
+	revoked, err := handler.IsRevoked(token.Claims.(*jwt.RegisteredClaims))
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestJWTRevocationRedisHandler_List(t *testing.T) {
+	handler := newTestRevocationHandler(t)
+	list, err := handler.List()
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+
+	_ = handler.Revoke(&jwt.RegisteredClaims{ID: "token-1", Subject: "test-user"})
+	_ = handler.RevokeSubject("other-user", time.Now())
+	list, err = handler.List()
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.ElementsMatch(t, []string{"test-user", "other-user"}, []string{list[0].Subject, list[1].Subject})
+}
+
+func TestJWTRevocationRedisHandler_List_PatternPrefix(t *testing.T) {
+	_, client := newTestRedisClient(t)
+	handler := utils.Redis.NewJWTRevocationHandler(utils.JWTRevocationRedisConfig{Client: client, KeyPrefix: "rev*"})
+	other := utils.Redis.NewJWTRevocationHandler(utils.JWTRevocationRedisConfig{Client: client, KeyPrefix: "revoked"})
+	_ = handler.Revoke(&jwt.RegisteredClaims{ID: "token-1", Subject: "test-user"})
+	_ = other.Revoke(&jwt.RegisteredClaims{ID: "token-2", Subject: "other-user"})
+
+	list, err := handler.List()
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, "test-user", list[0].Subject)
+}
+
+func TestEchoJWTUtil_JWTAuth_Revocation(t *testing.T) {
+	handler := newTestRevocationHandler(t)
+	config := *testEchoJWTConfig
+	config.RevocationHandler = handler
+	echoJWT := utils.EchoJWT.New(&config)
+	e := echo.New()
+	e.Use(echoJWT.JWTAuth())
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	serve := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", token))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	token := echoJWT.CreateToken(testClaims)
+	assert.Equal(t, http.StatusOK, serve(token.SignedString))
+	assert.NoError(t, handler.Revoke(&token.Claims))
+	assert.Equal(t, http.StatusUnauthorized, serve(token.SignedString))
+
+	_, err := echoJWT.ParseTokenFunc(nil, token.SignedString)
+	assert.ErrorIs(t, err, utils.ErrJWTRevoked)
+}
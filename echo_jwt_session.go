@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"reflect"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// DefaultJWTSessionContextKey is the echo.Context key of the session loaded by the JWT middleware
+const DefaultJWTSessionContextKey = "session"
+
+// sessionMetaType is the reflect.Type of SessionMeta
+var sessionMetaType = reflect.TypeOf(SessionMeta{})
+
+// JWTSessionClaims are the claims of tokens bound to a session,
+// embedding the session metadata as the sid, uid and gid claims
+type JWTSessionClaims struct {
+	jwt.RegisteredClaims
+	SessionMeta
+}
+
+// JWTSessionToken is a helper struct for returning signed JWT tokens bound to a session
+type JWTSessionToken struct {
+	SignedString string           // The signed token as a string
+	Claims       JWTSessionClaims // The claims included in the token
+	Session      Session          // The session persisted for the token
+}
+
+// CreateSessionToken creates a token embedding the session metadata in its claims
+// and persists the session with the SessionHandler until the token expires.
+// The session ID defaults to the token ID.
+func (eJWT EchoJWTUtil) CreateSessionToken(claims jwt.RegisteredClaims, session Session) (JWTSessionToken, error) {
+	if eJWT.Config.SessionHandler == nil {
+		return JWTSessionToken{}, ErrSessionHandlerNotConfigured
+	}
+	eJWT.setDefaultClaims(&claims)
+	if session.Meta.ID == "" {
+		session.Meta.ID = claims.ID
+	}
+	sessionClaims := JWTSessionClaims{
+		RegisteredClaims: claims,
+		SessionMeta:      session.Meta,
+	}
+	signedToken, err := eJWT.signToken(sessionClaims)
+	if err != nil {
+		return JWTSessionToken{}, err
+	}
+	if err := eJWT.Config.SessionHandler.Set(session, claims.ExpiresAt.Unix()); err != nil {
+		return JWTSessionToken{}, err
+	}
+	return JWTSessionToken{
+		SignedString: signedToken,
+		Claims:       sessionClaims,
+		Session:      session,
+	}, nil
+}
+
+// GetSession returns the session loaded by the JWT middleware
+func (eJWT EchoJWTUtil) GetSession(c echo.Context) (Session, error) {
+	session, ok := c.Get(DefaultJWTSessionContextKey).(Session)
+	if !ok {
+		return Session{}, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+// loadSession verifies that the session of the token still exists
+// and stores it in the echo.Context when a SessionHandler is configured
+func (eJWT EchoJWTUtil) loadSession(c echo.Context, token *jwt.Token) error {
+	if eJWT.Config.SessionHandler == nil {
+		return nil
+	}
+	meta := sessionMetaOf(token.Claims)
+	if meta == nil || meta.ID == "" {
+		return ErrSessionInvalid
+	}
+	session, err := eJWT.Config.SessionHandler.Get(*meta)
+	if err != nil {
+		return err
+	}
+	if c != nil {
+		c.Set(DefaultJWTSessionContextKey, session)
+	}
+	return nil
+}
+
+// sessionMetaOf returns the SessionMeta embedded in the given claims, or nil if not found
+func sessionMetaOf(claims any) *SessionMeta {
+	if f, ok := embeddedFieldOf(claims, sessionMetaType); ok {
+		return f.Interface().(*SessionMeta)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package utils_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+func newTestSessionEchoJWT(t *testing.T) *utils.EchoJWTUtil {
+	_, client := newTestRedisClient(t)
+	config := *testEchoJWTConfig
+	config.SessionHandler = utils.Redis.NewSessionHandler(utils.SessionRedisConfig{
+		Client:                 client,
+		MultipleSessionPerUser: true,
+	})
+	return utils.EchoJWT.New(&config)
+}
+
+func TestEchoJWTUtil_CreateSessionToken(t *testing.T) {
+	echoJWT := newTestSessionEchoJWT(t)
+	token, err := echoJWT.CreateSessionToken(testClaims, utils.Session{
+		Meta: utils.SessionMeta{UserID: 1, GroupID: "group-1"},
+		Data: "data",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, token.Claims.RegisteredClaims.ID, token.Claims.SessionMeta.ID)
+	assert.Equal(t, int64(1), token.Claims.UserID)
+
+	exists, err := echoJWT.Config.SessionHandler.Exists(token.Session.Meta)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	result, err := echoJWT.ParseToken(token.SignedString)
+	assert.NoError(t, err)
+	assert.Equal(t, &token.Claims, result.Claims)
+
+	_, err = utils.EchoJWT.New(testEchoJWTConfig).CreateSessionToken(testClaims, utils.Session{})
+	assert.ErrorIs(t, err, utils.ErrSessionHandlerNotConfigured)
+}
+
+func TestEchoJWTUtil_JWTAuth_Session(t *testing.T) {
+	echoJWT := newTestSessionEchoJWT(t)
+	e := echo.New()
+	e.Use(echoJWT.JWTAuth())
+	e.GET("/", func(c echo.Context) error {
+		session, err := echoJWT.GetSession(c)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, session.Data)
+	})
+	serve := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", token))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	token, _ := echoJWT.CreateSessionToken(testClaims, utils.Session{
+		Meta: utils.SessionMeta{UserID: 1, GroupID: "group-1"},
+		Data: "data",
+	})
+	rec := serve(token.SignedString)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "\"data\"\n", rec.Body.String())
+
+	assert.NoError(t, echoJWT.Config.SessionHandler.DeleteByUserID(1))
+	assert.Equal(t, http.StatusUnauthorized, serve(token.SignedString).Code)
+	_, err := echoJWT.ParseTokenFunc(nil, token.SignedString)
+	assert.ErrorIs(t, err, utils.ErrSessionNotFound)
+
+	plain := utils.EchoJWT.New(testEchoJWTConfig).CreateToken(jwt.RegisteredClaims{Subject: "test-user"})
+	_, err = echoJWT.ParseTokenFunc(nil, plain.SignedString)
+	assert.ErrorIs(t, err, utils.ErrSessionInvalid)
+}
+
+func TestEchoJWTUtil_GetSession_NotFound(t *testing.T) {
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	_, err := utils.EchoJWT.GetSession(c)
+	assert.ErrorIs(t, err, utils.ErrSessionNotFound)
+}
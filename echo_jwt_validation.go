@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/exp/slices"
+)
+
+// Unauthorized error code constants, subtypes of ErrCodeUnauthorized for invalid tokens
+const (
+	ErrCodeUnauthorizedTokenExpired     = "UNAUTHORIZED_TOKEN_EXPIRED"
+	ErrCodeUnauthorizedTokenNotValidYet = "UNAUTHORIZED_TOKEN_NOT_VALID_YET"
+	ErrCodeUnauthorizedTokenMalformed   = "UNAUTHORIZED_TOKEN_MALFORMED"
+	ErrCodeUnauthorizedTokenInvalid     = "UNAUTHORIZED_TOKEN_INVALID"
+	ErrCodeUnauthorizedTokenRevoked     = "UNAUTHORIZED_TOKEN_REVOKED"
+	ErrCodeUnauthorizedInvalidIssuer    = "UNAUTHORIZED_INVALID_ISSUER"
+	ErrCodeUnauthorizedInvalidAudience  = "UNAUTHORIZED_INVALID_AUDIENCE"
+)
+
+// ErrJWTTooOld is returned when a token was issued longer than MaxTokenAge ago
+var ErrJWTTooOld = errors.New("token is too old")
+
+// jwtTimeValidationErrors are the validation errors of the time based registered claims,
+// which validateClaims checks itself with the configured clock skew
+const jwtTimeValidationErrors = jwt.ValidationErrorExpired | jwt.ValidationErrorNotValidYet | jwt.ValidationErrorIssuedAt
+
+// validateClaims validates the registered claims of a token using the configuration.
+// Custom claims are also validated by their Valid method, ignoring the time based
+// errors of the embedded registered claims, which are checked with the clock skew.
+func (eJWT EchoJWTUtil) validateClaims(claims jwt.Claims) error {
+	registered := registeredClaimsOf(claims)
+	if registered == nil {
+		return claims.Valid()
+	}
+	if err := eJWT.validateRegisteredClaims(registered); err != nil {
+		return err
+	}
+	if _, ok := claims.(*jwt.RegisteredClaims); ok {
+		return nil
+	}
+	err := claims.Valid()
+	var validationErr *jwt.ValidationError
+	if errors.As(err, &validationErr) && validationErr.Errors&^jwtTimeValidationErrors == 0 {
+		return nil
+	}
+	return err
+}
+
+// validateRegisteredClaims validates the registered claims against the configured
+// issuers, audiences, clock skew and maximum token age
+func (eJWT EchoJWTUtil) validateRegisteredClaims(registered *jwt.RegisteredClaims) error {
+	now := time.Now()
+	skew := eJWT.Config.ClockSkew
+	if registered.ExpiresAt != nil && now.After(registered.ExpiresAt.Add(skew)) {
+		return &jwt.ValidationError{Inner: jwt.ErrTokenExpired, Errors: jwt.ValidationErrorExpired}
+	}
+	if registered.NotBefore != nil && now.Add(skew).Before(registered.NotBefore.Time) {
+		return &jwt.ValidationError{Inner: jwt.ErrTokenNotValidYet, Errors: jwt.ValidationErrorNotValidYet}
+	}
+	if registered.IssuedAt != nil && now.Add(skew).Before(registered.IssuedAt.Time) {
+		return &jwt.ValidationError{Inner: jwt.ErrTokenUsedBeforeIssued, Errors: jwt.ValidationErrorIssuedAt}
+	}
+	if eJWT.Config.MaxTokenAge > 0 {
+		if registered.IssuedAt == nil || now.After(registered.IssuedAt.Add(eJWT.Config.MaxTokenAge+skew)) {
+			return &jwt.ValidationError{Inner: ErrJWTTooOld, Errors: jwt.ValidationErrorExpired}
+		}
+	}
+	if issuers := eJWT.expectedIssuers(); len(issuers) > 0 && !slices.Contains(issuers, registered.Issuer) {
+		return &jwt.ValidationError{Inner: jwt.ErrTokenInvalidIssuer, Errors: jwt.ValidationErrorIssuer}
+	}
+	if audiences := eJWT.expectedAudiences(); len(audiences) > 0 && !containsAny(registered.Audience, audiences) {
+		return &jwt.ValidationError{Inner: jwt.ErrTokenInvalidAudience, Errors: jwt.ValidationErrorAudience}
+	}
+	return nil
+}
+
+// expectedIssuers returns the issuers accepted when parsing tokens
+func (eJWT EchoJWTUtil) expectedIssuers() []string {
+	if eJWT.Config.Issuer == "" {
+		return eJWT.Config.ExpectedIssuers
+	}
+	return append([]string{eJWT.Config.Issuer}, eJWT.Config.ExpectedIssuers...)
+}
+
+// expectedAudiences returns the audiences accepted when parsing tokens
+func (eJWT EchoJWTUtil) expectedAudiences() []string {
+	return append(slices.Clone(eJWT.Config.Audience), eJWT.Config.ExpectedAudiences...)
+}
+
+// jwtErrorCode returns the unauthorized error code subtype of a token error,
+// or an empty string if the error is not a token error
+func jwtErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrJWTRevoked):
+		return ErrCodeUnauthorizedTokenRevoked
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return ErrCodeUnauthorizedTokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return ErrCodeUnauthorizedTokenNotValidYet
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return ErrCodeUnauthorizedInvalidIssuer
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return ErrCodeUnauthorizedInvalidAudience
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return ErrCodeUnauthorizedTokenMalformed
+	}
+	var validationErr *jwt.ValidationError
+	if errors.As(err, &validationErr) {
+		return ErrCodeUnauthorizedTokenInvalid
+	}
+	return ""
+}
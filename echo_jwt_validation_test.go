@@ -0,0 +1,163 @@
+package utils_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+func TestEchoJWTUtil_ParseToken_Validation(t *testing.T) {
+	echoJWT := utils.EchoJWT.New(&utils.EchoJWTConfig{
+		SigningKey:        "my-secret-key",
+		ExpiresTTL:        time.Hour,
+		Issuer:            "issuer-1",
+		Audience:          []string{"api"},
+		ExpectedIssuers:   []string{"issuer-2"},
+		ExpectedAudiences: []string{"admin"},
+		ClockSkew:         time.Minute,
+		MaxTokenAge:       2 * time.Hour,
+	})
+	now := time.Now()
+	tests := []struct {
+		name   string
+		claims jwt.RegisteredClaims
+		err    error
+		code   string
+	}{
+		{
+			name:   "defaults",
+			claims: jwt.RegisteredClaims{Subject: "test-user"},
+		},
+		{
+			name:   "expected issuer and audience",
+			claims: jwt.RegisteredClaims{Issuer: "issuer-2", Audience: jwt.ClaimStrings{"admin"}},
+		},
+		{
+			name:   "expired within skew",
+			claims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(now.Add(-30 * time.Second))},
+		},
+		{
+			name:   "expired",
+			claims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(now.Add(-2 * time.Minute))},
+			err:    jwt.ErrTokenExpired,
+			code:   utils.ErrCodeUnauthorizedTokenExpired,
+		},
+		{
+			name:   "not valid yet",
+			claims: jwt.RegisteredClaims{NotBefore: jwt.NewNumericDate(now.Add(2 * time.Minute))},
+			err:    jwt.ErrTokenNotValidYet,
+			code:   utils.ErrCodeUnauthorizedTokenNotValidYet,
+		},
+		{
+			name:   "too old",
+			claims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(now.Add(-3 * time.Hour))},
+			err:    utils.ErrJWTTooOld,
+			code:   utils.ErrCodeUnauthorizedTokenExpired,
+		},
+		{
+			name:   "invalid issuer",
+			claims: jwt.RegisteredClaims{Issuer: "other"},
+			err:    jwt.ErrTokenInvalidIssuer,
+			code:   utils.ErrCodeUnauthorizedInvalidIssuer,
+		},
+		{
+			name:   "invalid audience",
+			claims: jwt.RegisteredClaims{Audience: jwt.ClaimStrings{"other"}},
+			err:    jwt.ErrTokenInvalidAudience,
+			code:   utils.ErrCodeUnauthorizedInvalidAudience,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			token := echoJWT.CreateToken(test.claims)
+			result, err := echoJWT.ParseToken(token.SignedString)
+			if test.err == nil {
+				assert.NoError(t, err)
+				assert.NotNil(t, result)
+				return
+			}
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, test.err)
+			resp := utils.ParseErrorResponse(err)
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+			assert.Equal(t, test.code, resp.ErrorCode)
+		})
+	}
+}
+
+type testValidClaims struct {
+	jwt.RegisteredClaims
+	TenantID string `json:"tid"`
+}
+
+func (c testValidClaims) Valid() error {
+	if err := c.RegisteredClaims.Valid(); err != nil {
+		return err
+	}
+	if c.TenantID == "banned" {
+		return errors.New("tenant is banned")
+	}
+	return nil
+}
+
+func TestEchoJWTClaimsUtil_ParseToken_Valid(t *testing.T) {
+	echoJWT := utils.NewEchoJWTClaims[testValidClaims](&utils.EchoJWTConfig{
+		SigningKey: "my-secret-key",
+		ExpiresTTL: time.Hour,
+		ClockSkew:  time.Minute,
+	})
+	token, err := echoJWT.CreateToken(testValidClaims{TenantID: "tenant-1"})
+	assert.NoError(t, err)
+	_, err = echoJWT.ParseToken(token.SignedString)
+	assert.NoError(t, err)
+
+	token, _ = echoJWT.CreateToken(testValidClaims{TenantID: "banned"})
+	_, err = echoJWT.ParseToken(token.SignedString)
+	assert.EqualError(t, err, "tenant is banned")
+
+	expiresAt := jwt.NewNumericDate(time.Now().Add(-30 * time.Second))
+	token, _ = echoJWT.CreateToken(testValidClaims{RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: expiresAt}})
+	_, err = echoJWT.ParseToken(token.SignedString)
+	assert.NoError(t, err)
+}
+
+func TestEchoJWTUtil_CreateToken_IssuerAudience(t *testing.T) {
+	echoJWT := utils.EchoJWT.New(&utils.EchoJWTConfig{
+		SigningKey: "my-secret-key",
+		ExpiresTTL: time.Hour,
+		Issuer:     "issuer-1",
+		Audience:   []string{"api"},
+	})
+	token := echoJWT.CreateToken(testClaims)
+	assert.Equal(t, "issuer-1", token.Claims.Issuer)
+	assert.Equal(t, jwt.ClaimStrings{"api"}, token.Claims.Audience)
+}
+
+func TestParseErrorResponse_JWTAuth(t *testing.T) {
+	echoJWT := utils.EchoJWT.New(testEchoJWTConfig)
+	e := echo.New()
+	e.Use(echoJWT.JWTAuth())
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	var handlerErr error
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		handlerErr = err
+	}
+	token := echoJWT.CreateToken(jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour))})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token.SignedString)
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	resp := utils.ParseErrorResponse(handlerErr)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, utils.ErrCodeUnauthorizedTokenExpired, resp.ErrorCode)
+	assert.Equal(t, utils.ErrMessageUnauthorized, resp.ErrorMessage)
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"errors"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// EchoSignatureConfig is the configuration for the VerifySignature middleware
+type EchoSignatureConfig struct {
+	SignatureVerifyConfig                    // The configuration for verifying signatures
+	Skipper               middleware.Skipper // Skipper defines a function to skip the middleware
+}
+
+// VerifySignature returns a middleware verifying the HMAC signature of requests,
+// e.g. webhooks, responding with ErrCodeUnauthorized for invalid signatures
+func (EchoUtil) VerifySignature(config EchoSignatureConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+			err := Signature.VerifyRequest(c.Request(), config.SignatureVerifyConfig)
+			if errors.Is(err, ErrSignatureMissing) || errors.Is(err, ErrSignatureInvalid) ||
+				errors.Is(err, ErrSignatureExpired) || errors.Is(err, ErrSignatureReplayed) {
+				return NewCommonErrorUnauthorized(err)
+			}
+			if err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
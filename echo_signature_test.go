@@ -0,0 +1,50 @@
+package utils_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+func TestEchoUtil_VerifySignature(t *testing.T) {
+	_, client := newTestRedisClient(t)
+	e := echo.New()
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		resp := utils.ParseErrorResponse(err)
+		_ = c.JSON(resp.StatusCode, resp)
+	}
+	e.POST("/webhook", func(c echo.Context) error {
+		body := map[string]any{}
+		if err := c.Bind(&body); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, body)
+	}, utils.Echo.VerifySignature(utils.EchoSignatureConfig{
+		SignatureVerifyConfig: utils.SignatureVerifyConfig{Secret: testSignatureSecret, Client: client},
+	}))
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	req := newTestSignedRequest(t, nil, `{"id":1}`)
+	replay := newTestSignedRequest(t, nil, `{"id":1}`)
+	replay.Header = req.Header.Clone()
+	rec := serve(req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id":1}`, rec.Body.String())
+
+	rec = serve(replay)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), utils.ErrSignatureReplayed.Error())
+
+	rec = serve(httptest.NewRequest(http.MethodPost, "/webhook", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
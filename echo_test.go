@@ -1,12 +1,14 @@
 package utils_test
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 
@@ -230,6 +232,64 @@ func TestEchoValidator_Validate(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestEchoValidator_Validator(t *testing.T) {
+	v := utils.NewValidator(utils.ValidatorConfig{})
+	_ = v.RegisterTag("echo_only", func(fl validator.FieldLevel) bool {
+		return fl.Field().String() == "echo"
+	}, map[string]string{"en": "{0} must be echo"})
+	data := struct {
+		Name string `json:"name" validate:"echo_only"`
+	}{Name: "echo"}
+	assert.NoError(t, utils.EchoValidator{Validator: v}.Validate(&data))
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"other"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	c := e.NewContext(req, httptest.NewRecorder())
+	binder := utils.EchoBinderWithValidation{Validator: v}
+	err := binder.Bind(&data, c)
+	assert.EqualError(t, err, "Validation failed for 'name'")
+	assert.Equal(t, "name must be echo", v.ParseError(err).Details[0].Message)
+}
+
+func TestEchoBinderWithValidation_Validator_AcceptLanguage(t *testing.T) {
+	_ = utils.RegisterValidationTag("plan_code", func(fl validator.FieldLevel) bool {
+		return true
+	}, map[string]string{"en": "{0} must be a default plan", "th": "{0} ต้องเป็นแผนเริ่มต้น"})
+	v := utils.NewValidator(utils.ValidatorConfig{})
+	_ = v.RegisterTag("plan_code", func(fl validator.FieldLevel) bool {
+		return fl.Field().String() == "gold"
+	}, map[string]string{"en": "{0} must be a plan code", "th": "{0} ต้องเป็นรหัสแผน"})
+
+	e := echo.New()
+	e.Binder = &utils.EchoBinderWithValidation{Validator: v}
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		resp := utils.ParseErrorResponse(err, c.Request().Header.Get("Accept-Language"))
+		_ = c.JSON(resp.StatusCode, resp)
+	}
+	e.POST("/plans", func(c echo.Context) error {
+		var req struct {
+			Plan string `json:"plan" validate:"plan_code"`
+		}
+		if err := c.Bind(&req); err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	for lang, message := range map[string]string{"th": "plan ต้องเป็นรหัสแผน", "en": "plan must be a plan code"} {
+		req := httptest.NewRequest(http.MethodPost, "/plans", strings.NewReader(`{"plan":"free"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("Accept-Language", lang)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		resp := utils.ErrorResponse{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, message, resp.ErrorValidation[0].Message)
+	}
+}
+
 func TestEchoBinderWithValidation_BindAll(t *testing.T) {
 	type TestRequest struct {
 		ID     int    `param:"id" validate:"required"`
@@ -0,0 +1,258 @@
+// Package echotest provides helpers for testing Echo handlers and middleware
+// built with the utils package.
+package echotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+// Request is a fluent builder for HTTP requests executed against Echo handlers
+type Request struct {
+	method      string
+	target      string
+	routePath   string
+	query       url.Values
+	paramNames  []string
+	paramValues []string
+	header      http.Header
+	body        []byte
+	err         error
+}
+
+// Response is the result of executing a Request
+type Response struct {
+	*httptest.ResponseRecorder
+	Context echo.Context // The context the request was executed with
+	Err     error        // The error returned by the handler, only set by Handle
+}
+
+// NewRequest creates a new Request with the given method and target URL
+func NewRequest(method, target string) *Request {
+	return &Request{
+		method: method,
+		target: target,
+		query:  url.Values{},
+		header: http.Header{},
+	}
+}
+
+// WithHeader sets a request header
+func (r *Request) WithHeader(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// WithQuery adds a query param to the target URL
+func (r *Request) WithQuery(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// WithPath sets the route path, e.g. "/users/:id", used by Handle
+func (r *Request) WithPath(routePath string) *Request {
+	r.routePath = routePath
+	return r
+}
+
+// WithParam sets a path param, used by Handle
+func (r *Request) WithParam(name, value string) *Request {
+	r.paramNames = append(r.paramNames, name)
+	r.paramValues = append(r.paramValues, value)
+	return r
+}
+
+// WithBody sets the raw request body and its content type
+func (r *Request) WithBody(contentType string, body string) *Request {
+	r.header.Set(echo.HeaderContentType, contentType)
+	r.body = []byte(body)
+	return r
+}
+
+// WithJSON sets the request body to the JSON encoding of v
+func (r *Request) WithJSON(v any) *Request {
+	b, err := json.Marshal(v)
+	if err != nil {
+		r.err = err
+	}
+	return r.WithBody(echo.MIMEApplicationJSON, string(b))
+}
+
+// WithBearerToken sets the Authorization header with the given bearer token
+func (r *Request) WithBearerToken(token string) *Request {
+	return r.WithHeader(echo.HeaderAuthorization, fmt.Sprintf("Bearer %s", token))
+}
+
+// WithJWT mints a token with EchoJWTUtil.CreateTokenWithError and sets it as bearer token
+func (r *Request) WithJWT(echoJWT *utils.EchoJWTUtil, claims jwt.RegisteredClaims) *Request {
+	token, err := echoJWT.CreateTokenWithError(claims)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	return r.WithBearerToken(token.SignedString)
+}
+
+// Build returns the http.Request, or an error if the request could not be built
+func (r *Request) Build() (*http.Request, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	target := r.target
+	if len(r.query) > 0 {
+		separator := "?"
+		if strings.Contains(target, "?") {
+			separator = "&"
+		}
+		target += separator + r.query.Encode()
+	}
+	req := httptest.NewRequest(r.method, target, bytes.NewReader(r.body))
+	for k, v := range r.header {
+		req.Header[k] = v
+	}
+	return req, nil
+}
+
+// Serve executes the request through the full Echo instance,
+// including routing, middleware and the HTTP error handler
+func (r *Request) Serve(t testing.TB, e *echo.Echo) *Response {
+	t.Helper()
+	req, err := r.Build()
+	if err != nil {
+		t.Fatalf("echotest: build request: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return &Response{ResponseRecorder: rec}
+}
+
+// Handle executes the request against a single handler wrapped with the given middleware,
+// using a context of an instance created by utils.Echo.New. The error returned by the
+// handler is not rendered and is available in Response.Err.
+func (r *Request) Handle(t testing.TB, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *Response {
+	t.Helper()
+	req, err := r.Build()
+	if err != nil {
+		t.Fatalf("echotest: build request: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	c := utils.Echo.New().NewContext(req, rec)
+	if r.routePath != "" {
+		c.SetPath(r.routePath)
+	}
+	c.SetParamNames(r.paramNames...)
+	c.SetParamValues(r.paramValues...)
+	for i := len(m) - 1; i >= 0; i-- {
+		h = m[i](h)
+	}
+	return &Response{
+		ResponseRecorder: rec,
+		Context:          c,
+		Err:              h(c),
+	}
+}
+
+// DecodeJSON decodes the response body into v
+func (r *Response) DecodeJSON(v any) error {
+	return json.Unmarshal(r.Body.Bytes(), v)
+}
+
+// ErrorResponse returns the ErrorResponse of the handler error if there is one,
+// otherwise the response body decoded into an ErrorResponse
+func (r *Response) ErrorResponse() (utils.ErrorResponse, error) {
+	if r.Err != nil {
+		return utils.ParseErrorResponse(r.Err), nil
+	}
+	resp := utils.ErrorResponse{}
+	err := r.DecodeJSON(&resp)
+	return resp, err
+}
+
+// AssertStatus asserts the response status code
+func (r *Response) AssertStatus(t testing.TB, statusCode int) bool {
+	t.Helper()
+	if r.Code != statusCode {
+		t.Errorf("unexpected response status code: expected %d, actual %d", statusCode, r.Code)
+		return false
+	}
+	return true
+}
+
+// AssertJSON asserts the response body is JSON equal to the encoding of expected
+func (r *Response) AssertJSON(t testing.TB, expected any) bool {
+	t.Helper()
+	b, err := json.Marshal(expected)
+	if err != nil {
+		t.Errorf("error encoding expected JSON: %v", err)
+		return false
+	}
+	var expectedJSON, actualJSON any
+	_ = json.Unmarshal(b, &expectedJSON)
+	if err := json.Unmarshal(r.Body.Bytes(), &actualJSON); err != nil {
+		t.Errorf("response body is not JSON: %v", err)
+		return false
+	}
+	if !reflect.DeepEqual(expectedJSON, actualJSON) {
+		t.Errorf("unexpected response body: expected %s, actual %s", b, strings.TrimSpace(r.Body.String()))
+		return false
+	}
+	return true
+}
+
+// AssertError asserts the ErrorResponse has the given status and error code
+func (r *Response) AssertError(t testing.TB, statusCode int, errorCode string) bool {
+	t.Helper()
+	resp, err := r.ErrorResponse()
+	if err != nil {
+		t.Errorf("response is not an ErrorResponse: %v", err)
+		return false
+	}
+	ok := true
+	if resp.StatusCode != statusCode {
+		t.Errorf("unexpected error status code: expected %d, actual %d", statusCode, resp.StatusCode)
+		ok = false
+	}
+	if resp.ErrorCode != errorCode {
+		t.Errorf("unexpected error code: expected %q, actual %q", errorCode, resp.ErrorCode)
+		ok = false
+	}
+	return ok
+}
+
+// AssertValidation asserts the ErrorResponse contains exactly the given validation details,
+// comparing fields and tags only, in order
+func (r *Response) AssertValidation(t testing.TB, expected ...utils.ValidationErrorDetail) bool {
+	t.Helper()
+	resp, err := r.ErrorResponse()
+	if err != nil {
+		t.Errorf("response is not an ErrorResponse: %v", err)
+		return false
+	}
+	expectedTags, actualTags := fieldTags(expected), fieldTags(resp.ErrorValidation)
+	if !reflect.DeepEqual(expectedTags, actualTags) {
+		t.Errorf("unexpected validation details: expected %v, actual %v", expectedTags, actualTags)
+		return false
+	}
+	return true
+}
+
+// fieldTags returns the field and tag pairs of the given validation details
+func fieldTags(details []utils.ValidationErrorDetail) []string {
+	result := make([]string, len(details))
+	for i, d := range details {
+		result[i] = d.Field + ":" + d.Tag
+	}
+	return result
+}
@@ -0,0 +1,168 @@
+package echotest_test
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+	"github.com/dollarsignteam/go-utils/echotest"
+)
+
+type testMember struct {
+	ID   int    `param:"id" validate:"required"`
+	Name string `json:"name" validate:"required"`
+	Page int    `query:"page" validate:"gte=1"`
+}
+
+// recordingT records the errors reported by the assertions instead of failing the test
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func testMemberHandler(c echo.Context) error {
+	member := testMember{}
+	if err := utils.EchoBinder.BindAll(&member, c); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, member)
+}
+
+func TestRequest_Handle(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		res := echotest.NewRequest(http.MethodPost, "/members/1").
+			WithPath("/members/:id").
+			WithParam("id", "1").
+			WithQuery("page", "2").
+			WithJSON(map[string]any{"name": "John"}).
+			Handle(t, testMemberHandler)
+		assert.NoError(t, res.Err)
+		res.AssertStatus(t, http.StatusOK)
+		member := testMember{}
+		assert.NoError(t, res.DecodeJSON(&member))
+		assert.Equal(t, testMember{ID: 1, Name: "John", Page: 2}, member)
+		assert.Equal(t, "/members/:id", res.Context.Path())
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		res := echotest.NewRequest(http.MethodPost, "/members/1").
+			WithParam("id", "1").
+			WithJSON(map[string]any{}).
+			Handle(t, testMemberHandler)
+		assert.Error(t, res.Err)
+		res.AssertError(t, http.StatusBadRequest, utils.ErrCodeBadRequest)
+		res.AssertValidation(t,
+			utils.ValidationErrorDetail{Field: "name", Tag: "required"},
+			utils.ValidationErrorDetail{Field: "Page", Tag: "gte"},
+		)
+	})
+
+	t.Run("middleware", func(t *testing.T) {
+		var order []string
+		middleware := func(name string) echo.MiddlewareFunc {
+			return func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					order = append(order, name)
+					return next(c)
+				}
+			}
+		}
+		res := echotest.NewRequest(http.MethodGet, "/").
+			Handle(t, utils.Echo.NoContentHandler, middleware("first"), middleware("second"))
+		res.AssertStatus(t, http.StatusNoContent)
+		assert.Equal(t, []string{"first", "second"}, order)
+	})
+}
+
+func TestRequest_Serve(t *testing.T) {
+	echoJWT := utils.EchoJWT.New(&utils.EchoJWTConfig{
+		SigningKey: "my-secret-key",
+		ExpiresTTL: time.Hour,
+	})
+	e := utils.Echo.New()
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		resp := utils.ParseErrorResponse(err)
+		_ = c.JSON(resp.StatusCode, resp)
+	}
+	e.GET("/me", func(c echo.Context) error {
+		claims, err := echoJWT.GetClaims(c.Get("user").(*jwt.Token))
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, echo.Map{"sub": claims.Subject, "lang": c.Request().Header.Get("Accept-Language")})
+	}, echoJWT.JWTAuth())
+
+	t.Run("authorized", func(t *testing.T) {
+		res := echotest.NewRequest(http.MethodGet, "/me").
+			WithHeader("Accept-Language", "th").
+			WithJWT(echoJWT, jwt.RegisteredClaims{Subject: "test-user"}).
+			Serve(t, e)
+		res.AssertStatus(t, http.StatusOK)
+		res.AssertJSON(t, echo.Map{"sub": "test-user", "lang": "th"})
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		res := echotest.NewRequest(http.MethodGet, "/me").
+			WithBearerToken("invalid").
+			Serve(t, e)
+		res.AssertStatus(t, http.StatusUnauthorized)
+		res.AssertError(t, http.StatusUnauthorized, utils.ErrCodeUnauthorizedTokenMalformed)
+	})
+}
+
+func TestRequest_Build(t *testing.T) {
+	req, err := echotest.NewRequest(http.MethodGet, "/search?q=go").
+		WithQuery("page", "1").
+		WithBody(echo.MIMETextPlain, "hello").
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "/search?q=go&page=1", req.URL.String())
+	assert.Equal(t, echo.MIMETextPlain, req.Header.Get(echo.HeaderContentType))
+
+	_, err = echotest.NewRequest(http.MethodPost, "/").WithJSON(make(chan int)).Build()
+	assert.Error(t, err)
+	publicKey, _, _ := ed25519.GenerateKey(nil)
+	verifyOnly := utils.EchoJWT.New(&utils.EchoJWTConfig{
+		Keys: []utils.JWTKey{{ID: "ed25519", Method: jwt.SigningMethodEdDSA, PublicKey: publicKey}},
+	})
+	_, err = echotest.NewRequest(http.MethodGet, "/").WithJWT(verifyOnly, jwt.RegisteredClaims{}).Build()
+	assert.ErrorIs(t, err, utils.ErrJWTKeyNotFound)
+}
+
+func TestResponse_ErrorResponse(t *testing.T) {
+	res := echotest.NewRequest(http.MethodGet, "/").Handle(t, func(c echo.Context) error {
+		return utils.NewCommonErrorBadRequest(errors.New("invalid input"))
+	})
+	resp, err := res.ErrorResponse()
+	assert.NoError(t, err)
+	assert.Equal(t, "invalid input", resp.ErrorMessage)
+}
+
+func TestResponse_AssertFailures(t *testing.T) {
+	res := echotest.NewRequest(http.MethodGet, "/").Handle(t, func(c echo.Context) error {
+		return c.JSON(http.StatusOK, echo.Map{"name": "test"})
+	})
+	rt := &recordingT{TB: t}
+	assert.False(t, res.AssertStatus(rt, http.StatusCreated))
+	assert.False(t, res.AssertJSON(rt, echo.Map{"name": "other"}))
+	assert.True(t, res.AssertJSON(rt, echo.Map{"name": "test"}))
+	assert.False(t, res.AssertError(rt, http.StatusBadRequest, utils.ErrCodeBadRequest))
+	assert.Equal(t, []string{
+		"unexpected response status code: expected 201, actual 200",
+		`unexpected response body: expected {"name":"other"}, actual {"name":"test"}`,
+		"unexpected error status code: expected 400, actual 0",
+		`unexpected error code: expected "BAD_REQUEST", actual ""`,
+	}, rt.errors)
+}
@@ -4,40 +4,65 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
-	"strings"
+	"runtime"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
+	"golang.org/x/exp/slices"
 )
 
 // Error code constants
 const (
-	ErrCodeSomethingWentWrong = "SOMETHING_WENT_WRONG"
-	ErrCodeBadRequest         = "BAD_REQUEST"
-	ErrCodeUnauthorized       = "UNAUTHORIZED"
-	ErrCodeNotFound           = "NOT_FOUND"
-	ErrCodeForbidden          = "FORBIDDEN"
-	ErrCodeMethodNotAllowed   = "METHOD_NOT_ALLOWED"
+	ErrCodeSomethingWentWrong   = "SOMETHING_WENT_WRONG"
+	ErrCodeBadRequest           = "BAD_REQUEST"
+	ErrCodeUnauthorized         = "UNAUTHORIZED"
+	ErrCodeNotFound             = "NOT_FOUND"
+	ErrCodeForbidden            = "FORBIDDEN"
+	ErrCodeMethodNotAllowed     = "METHOD_NOT_ALLOWED"
+	ErrCodeRequestTimeout       = "REQUEST_TIMEOUT"
+	ErrCodeConflict             = "CONFLICT"
+	ErrCodeGone                 = "GONE"
+	ErrCodePayloadTooLarge      = "PAYLOAD_TOO_LARGE"
+	ErrCodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	ErrCodeUnprocessableEntity  = "UNPROCESSABLE_ENTITY"
+	ErrCodeTooManyRequests      = "TOO_MANY_REQUESTS"
+	ErrCodeNotImplemented       = "NOT_IMPLEMENTED"
+	ErrCodeBadGateway           = "BAD_GATEWAY"
+	ErrCodeServiceUnavailable   = "SERVICE_UNAVAILABLE"
+	ErrCodeGatewayTimeout       = "GATEWAY_TIMEOUT"
 )
 
 // Error message constants
 const (
-	ErrMessageSomethingWentWrong = "Something went wrong"
-	ErrMessageBadRequest         = "Bad request"
-	ErrMessageUnauthorized       = "Unauthorized"
-	ErrMessageNotFound           = "Not found"
-	ErrMessageForbidden          = "Forbidden"
-	ErrMessageMethodNotAllowed   = "Method not allowed"
+	ErrMessageSomethingWentWrong   = "Something went wrong"
+	ErrMessageBadRequest           = "Bad request"
+	ErrMessageUnauthorized         = "Unauthorized"
+	ErrMessageNotFound             = "Not found"
+	ErrMessageForbidden            = "Forbidden"
+	ErrMessageMethodNotAllowed     = "Method not allowed"
+	ErrMessageRequestTimeout       = "Request timeout"
+	ErrMessageConflict             = "Conflict"
+	ErrMessageGone                 = "Gone"
+	ErrMessagePayloadTooLarge      = "Payload too large"
+	ErrMessageUnsupportedMediaType = "Unsupported media type"
+	ErrMessageUnprocessableEntity  = "Unprocessable entity"
+	ErrMessageTooManyRequests      = "Too many requests"
+	ErrMessageNotImplemented       = "Not implemented"
+	ErrMessageBadGateway           = "Bad gateway"
+	ErrMessageServiceUnavailable   = "Service unavailable"
+	ErrMessageGatewayTimeout       = "Gateway timeout"
 )
 
 const errMessageValidationFailed = "Key: '%s', Error: Validation for '%s' failed on the '%s' tag"
 
 // CommonError type for generic errors with status codes and error codes
 type CommonError struct {
-	StatusCode    int    `json:"statusCode"`   // HTTP status code
-	ErrorCode     string `json:"errorCode"`    // Specific error code
-	ErrorInstance error  `json:"errorMessage"` // The actual error instance
+	StatusCode    int         `json:"statusCode"`   // HTTP status code
+	ErrorCode     string      `json:"errorCode"`    // Specific error code
+	ErrorInstance error       `json:"errorMessage"` // The actual error instance
+	stack         *errorStack // The call stack captured at construction time, if enabled
 }
 
 // ValidationError represents an error related to validation, with error details.
@@ -45,13 +70,17 @@ type ValidationError struct {
 	ErrorMessage string                     `json:"errorMessage"`      // Overall error message
 	Details      []ValidationErrorDetail    `json:"details,omitempty"` // Optional list of error details
 	Errors       validator.ValidationErrors `json:"-"`                 // The actual validation errors
+	validator    *Validator                 // The validator that produced the errors, used to translate their messages
 }
 
 // ValidationErrorDetail represents an individual error detail, with a field, tag, and message.
 type ValidationErrorDetail struct {
-	Field   string `json:"field" example:"ID"`                                                                              // Field that caused the validation error
-	Tag     string `json:"tag" example:"required"`                                                                          // Validation tag that caused the error
-	Message string `json:"message" example:"Key: 'Member.ID' Error:Field validation for 'ID' failed on the 'required' tag"` // Full error message
+	Field   string `json:"field" example:"price"`                       // Field that caused the validation error
+	Path    string `json:"path" example:"items[2].price"`               // Path of the field using the JSON tag names
+	Tag     string `json:"tag" example:"required"`                      // Validation tag that caused the error
+	Param   string `json:"param,omitempty" example:"3"`                 // Parameter of the validation tag, e.g. 3 for min=3
+	Value   any    `json:"value,omitempty"`                             // Value of the field, only set by ValidateStruct and redacted for sensitive fields
+	Message string `json:"message" example:"price is a required field"` // Human-readable error message
 }
 
 // ErrorResponse represents an error response with error code, message, description, and validation errors
@@ -61,6 +90,7 @@ type ErrorResponse struct {
 	ErrorMessage     string                  `json:"errorMessage,omitempty" example:"Oops, something went wrong!"` // Custom error message
 	ErrorDescription string                  `json:"errorDescription,omitempty" example:"Something went wrong"`    // The actual error message
 	ErrorValidation  []ValidationErrorDetail `json:"errorValidation,omitempty"`                                    // List of validation errors
+	ErrorItems       []ErrorItemResponse     `json:"errorItems,omitempty"`                                         // List of errors of the items of a MultiError
 }
 
 // Error function for CommonError to return the error message
@@ -99,134 +129,278 @@ func (e *CommonError) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// Unwrap returns the underlying error instance
+func (e CommonError) Unwrap() error {
+	return e.ErrorInstance
+}
+
+// Format formats the error, printing the captured call stack with %+v
+func (e CommonError) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		_, _ = io.WriteString(s, e.Error())
+		if e.stack != nil {
+			e.stack.writeTo(s)
+		}
+	case verb == 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		_, _ = io.WriteString(s, e.Error())
+	}
+}
+
+// StackTrace returns the call stack captured when the error was created,
+// or nil when stack capture is disabled
+func (e CommonError) StackTrace() []runtime.Frame {
+	if e.stack == nil {
+		return nil
+	}
+	return e.stack.frames()
+}
+
 // Error function for ValidationError to return the error message
 func (e ValidationError) Error() string {
 	return e.ErrorMessage
 }
 
-// NewCommonErrorSomethingWentWrong creates a new CommonError instance
-// with `Something went wrong`  ErrorInstance field.
-func NewCommonErrorSomethingWentWrong(err error) CommonError {
+// Unwrap returns the underlying validation errors
+func (e ValidationError) Unwrap() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e.Errors
+}
+
+// newCommonError creates a new CommonError instance, capturing the call stack of the caller
+// of the constructor when stack capture is enabled
+func newCommonError(statusCode int, code string, err error) CommonError {
 	return CommonError{
-		StatusCode:    http.StatusInternalServerError,
-		ErrorCode:     ErrCodeSomethingWentWrong,
+		StatusCode:    statusCode,
+		ErrorCode:     code,
 		ErrorInstance: err,
+		stack:         captureErrorStack(),
 	}
 }
 
+// NewCommonErrorSomethingWentWrong creates a new CommonError instance
+// with `Something went wrong`  ErrorInstance field.
+func NewCommonErrorSomethingWentWrong(err error) CommonError {
+	return newCommonError(http.StatusInternalServerError, ErrCodeSomethingWentWrong, err)
+}
+
 // NewCommonErrorBadRequest creates a new CommonError instance
 // with `Bad request`  ErrorInstance field.
 func NewCommonErrorBadRequest(err error) CommonError {
-	return CommonError{
-		StatusCode:    http.StatusBadRequest,
-		ErrorCode:     ErrCodeBadRequest,
-		ErrorInstance: err,
-	}
+	return newCommonError(http.StatusBadRequest, ErrCodeBadRequest, err)
+}
+
+// NewCommonErrorUnauthorized creates a new CommonError instance
+// with `Unauthorized`  ErrorInstance field.
+func NewCommonErrorUnauthorized(err error) CommonError {
+	return newCommonError(http.StatusUnauthorized, ErrCodeUnauthorized, err)
+}
+
+// NewCommonErrorForbidden creates a new CommonError instance
+// with `Forbidden`  ErrorInstance field.
+func NewCommonErrorForbidden(err error) CommonError {
+	return newCommonError(http.StatusForbidden, ErrCodeForbidden, err)
+}
+
+// NewCommonErrorNotFound creates a new CommonError instance
+// with `Not found`  ErrorInstance field.
+func NewCommonErrorNotFound(err error) CommonError {
+	return newCommonError(http.StatusNotFound, ErrCodeNotFound, err)
+}
+
+// NewCommonErrorMethodNotAllowed creates a new CommonError instance
+// with `Method not allowed`  ErrorInstance field.
+func NewCommonErrorMethodNotAllowed(err error) CommonError {
+	return newCommonError(http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, err)
+}
+
+// NewCommonErrorRequestTimeout creates a new CommonError instance
+// with `Request timeout`  ErrorInstance field.
+func NewCommonErrorRequestTimeout(err error) CommonError {
+	return newCommonError(http.StatusRequestTimeout, ErrCodeRequestTimeout, err)
+}
+
+// NewCommonErrorConflict creates a new CommonError instance
+// with `Conflict`  ErrorInstance field.
+func NewCommonErrorConflict(err error) CommonError {
+	return newCommonError(http.StatusConflict, ErrCodeConflict, err)
+}
+
+// NewCommonErrorGone creates a new CommonError instance
+// with `Gone`  ErrorInstance field.
+func NewCommonErrorGone(err error) CommonError {
+	return newCommonError(http.StatusGone, ErrCodeGone, err)
 }
 
-// IsCommonError returns true if the given error is a CommonError.
+// NewCommonErrorPayloadTooLarge creates a new CommonError instance
+// with `Payload too large`  ErrorInstance field.
+func NewCommonErrorPayloadTooLarge(err error) CommonError {
+	return newCommonError(http.StatusRequestEntityTooLarge, ErrCodePayloadTooLarge, err)
+}
+
+// NewCommonErrorUnsupportedMediaType creates a new CommonError instance
+// with `Unsupported media type`  ErrorInstance field.
+func NewCommonErrorUnsupportedMediaType(err error) CommonError {
+	return newCommonError(http.StatusUnsupportedMediaType, ErrCodeUnsupportedMediaType, err)
+}
+
+// NewCommonErrorUnprocessableEntity creates a new CommonError instance
+// with `Unprocessable entity`  ErrorInstance field.
+func NewCommonErrorUnprocessableEntity(err error) CommonError {
+	return newCommonError(http.StatusUnprocessableEntity, ErrCodeUnprocessableEntity, err)
+}
+
+// NewCommonErrorTooManyRequests creates a new CommonError instance
+// with `Too many requests`  ErrorInstance field.
+func NewCommonErrorTooManyRequests(err error) CommonError {
+	return newCommonError(http.StatusTooManyRequests, ErrCodeTooManyRequests, err)
+}
+
+// NewCommonErrorNotImplemented creates a new CommonError instance
+// with `Not implemented`  ErrorInstance field.
+func NewCommonErrorNotImplemented(err error) CommonError {
+	return newCommonError(http.StatusNotImplemented, ErrCodeNotImplemented, err)
+}
+
+// NewCommonErrorBadGateway creates a new CommonError instance
+// with `Bad gateway`  ErrorInstance field.
+func NewCommonErrorBadGateway(err error) CommonError {
+	return newCommonError(http.StatusBadGateway, ErrCodeBadGateway, err)
+}
+
+// NewCommonErrorServiceUnavailable creates a new CommonError instance
+// with `Service unavailable`  ErrorInstance field.
+func NewCommonErrorServiceUnavailable(err error) CommonError {
+	return newCommonError(http.StatusServiceUnavailable, ErrCodeServiceUnavailable, err)
+}
+
+// NewCommonErrorGatewayTimeout creates a new CommonError instance
+// with `Gateway timeout`  ErrorInstance field.
+func NewCommonErrorGatewayTimeout(err error) CommonError {
+	return newCommonError(http.StatusGatewayTimeout, ErrCodeGatewayTimeout, err)
+}
+
+// IsCommonError returns true if the given error is or wraps a CommonError.
 func IsCommonError(err error) bool {
-	_, ok := err.(CommonError)
-	return ok
+	var e CommonError
+	return errors.As(err, &e)
 }
 
-// ParseCommonError checks if the given error is a CommonError instance and returns it.
+// ParseCommonError checks if the given error is or wraps a CommonError instance and returns it.
 // If not, it creates a new CommonError instance using NewCommonErrorSomethingWentWrong.
 func ParseCommonError(err error) CommonError {
-	if e, ok := err.(CommonError); ok {
+	var e CommonError
+	if errors.As(err, &e) {
 		return e
 	}
 	return NewCommonErrorSomethingWentWrong(err)
 }
 
-// IsValidationError returns true if the given error is a IsValidationError.
+// IsValidationError returns true if the given error is or wraps a ValidationError.
 func IsValidationError(err error) bool {
-	_, ok := err.(ValidationError)
-	return ok
+	var e ValidationError
+	return errors.As(err, &e)
 }
 
-// ParseValidationError converts an error into a ValidationError.
-// If the input error is a ValidationError, it's returned as is.
+// ParseValidationError converts an error into a ValidationError using DefaultValidator.
+// If the input error is or wraps a ValidationError, it's returned as is.
 func ParseValidationError(err error) ValidationError {
-	switch err := err.(type) {
-	case ValidationError:
-		return err
-	case validator.ValidationErrors:
-		errDetailList := make([]ValidationErrorDetail, len(err))
-		fieldList := make([]string, len(err))
-		for i, e := range err {
-			fieldList[i] = fmt.Sprintf("'%s'", e.Field())
-			errDetailList[i] = ValidationErrorDetail{
-				Field:   e.Field(),
-				Tag:     e.Tag(),
-				Message: fmt.Sprintf(errMessageValidationFailed, e.StructNamespace(), e.Field(), e.Tag()),
-			}
-		}
-		return ValidationError{
-			ErrorMessage: fmt.Sprintf("Validation failed for %s", strings.Join(fieldList, ", ")),
-			Details:      errDetailList,
-			Errors:       err,
-		}
-	default:
-		return ValidationError{
-			ErrorMessage: err.Error(),
-		}
+	return DefaultValidator.ParseError(err)
+}
+
+// parseLocalizedValidationError converts an error into a ValidationError with the messages of its
+// details translated to the optional Accept-Language value by the validator that produced it
+func parseLocalizedValidationError(err error, acceptLanguage []string) ValidationError {
+	validationErr := ParseValidationError(err)
+	if len(acceptLanguage) > 0 {
+		validationErr = TranslateValidationError(validationErr, acceptLanguage[0])
 	}
+	return validationErr
+}
+
+// isValidationError reports whether the error is or wraps a ValidationError or validator.ValidationErrors
+func isValidationError(err error) bool {
+	var validationErrs validator.ValidationErrors
+	return IsValidationError(err) || errors.As(err, &validationErrs)
+}
+
+// httpErrorDefaultMessageStatuses are the statuses of echo.HTTPError responses
+// that always use the default message of their error code
+var httpErrorDefaultMessageStatuses = []int{
+	http.StatusBadRequest,
+	http.StatusUnauthorized,
+	http.StatusForbidden,
+	http.StatusNotFound,
+	http.StatusMethodNotAllowed,
 }
 
 // ParseErrorResponse converts an error into a ErrorResponse.
-// If the input error is a ErrorResponse, it's returned as is.
-func ParseErrorResponse(err error) ErrorResponse {
+// Wrapped MultiError, CommonError, echo.HTTPError and validation errors are matched using errors.As.
+// The default messages of registered error codes and validation messages are
+// localized to the optional Accept-Language value, falling back to English.
+func ParseErrorResponse(err error, acceptLanguage ...string) ErrorResponse {
 	resp := ErrorResponse{
 		StatusCode:   http.StatusInternalServerError,
 		ErrorCode:    ErrCodeSomethingWentWrong,
 		ErrorMessage: err.Error(),
 	}
-	switch err := err.(type) {
-	case *echo.HTTPError:
-		resp.StatusCode = err.Code
-		switch resp.StatusCode {
-		case http.StatusBadRequest:
-			resp.ErrorCode = ErrCodeBadRequest
-			resp.ErrorMessage = ErrMessageBadRequest
-		case http.StatusUnauthorized:
-			resp.ErrorCode = ErrCodeUnauthorized
-			resp.ErrorMessage = ErrMessageUnauthorized
-		case http.StatusNotFound:
-			resp.ErrorCode = ErrCodeNotFound
-			resp.ErrorMessage = ErrMessageNotFound
-		case http.StatusForbidden:
-			resp.ErrorCode = ErrCodeForbidden
-			resp.ErrorMessage = ErrMessageForbidden
-		case http.StatusMethodNotAllowed:
-			resp.ErrorCode = ErrCodeMethodNotAllowed
-			resp.ErrorMessage = ErrMessageMethodNotAllowed
-		default:
-			message := fmt.Sprintf("%v", err.Message)
-			if err.Internal != nil {
-				message = fmt.Sprintf("%s, %s", message, err.Internal)
-			}
+	var multiErr MultiError
+	var commonErr CommonError
+	var httpErr *echo.HTTPError
+	switch {
+	case errors.As(err, &multiErr):
+		resp = parseMultiErrorResponse(multiErr, acceptLanguage)
+	case errors.As(err, &commonErr):
+		resp.StatusCode = commonErr.StatusCode
+		resp.ErrorCode = commonErr.ErrorCode
+		if message, ok := errorMessage(commonErr.ErrorCode); ok && commonErr.ErrorInstance == nil {
 			resp.ErrorMessage = message
 		}
-	case CommonError:
-		resp.StatusCode = err.StatusCode
-		resp.ErrorCode = err.ErrorCode
-		if err.ErrorInstance != nil {
-			resp.ErrorMessage = err.ErrorInstance.Error()
-			switch e := err.ErrorInstance.(type) {
-			case ValidationError, validator.ValidationErrors:
-				errValidator := ParseValidationError(e)
+		if commonErr.ErrorInstance != nil {
+			resp.ErrorMessage = commonErr.ErrorInstance.Error()
+			if isValidationError(commonErr.ErrorInstance) {
+				errValidator := parseLocalizedValidationError(commonErr.ErrorInstance, acceptLanguage)
 				resp.ErrorMessage = errValidator.ErrorMessage
 				resp.ErrorValidation = errValidator.Details
 			}
 		}
-	case ValidationError, validator.ValidationErrors:
-		errValidator := ParseValidationError(err)
+	case errors.As(err, &httpErr):
+		resp.StatusCode = httpErr.Code
+		resp.ErrorMessage = fmt.Sprintf("%v", httpErr.Message)
+		if httpErr.Internal != nil {
+			resp.ErrorMessage = fmt.Sprintf("%s, %s", resp.ErrorMessage, httpErr.Internal)
+		}
+		if code, ok := statusErrorCode(httpErr.Code); ok {
+			resp.ErrorCode = code
+			if slices.Contains(httpErrorDefaultMessageStatuses, httpErr.Code) || httpErr.Message == http.StatusText(httpErr.Code) {
+				resp.ErrorMessage, _ = errorMessage(code)
+			}
+			if httpErr.Code == http.StatusUnauthorized {
+				if code := jwtErrorCode(httpErr.Internal); code != "" {
+					resp.ErrorCode = code
+				}
+			}
+		}
+	case isValidationError(err):
+		errValidator := parseLocalizedValidationError(err, acceptLanguage)
 		resp.StatusCode = http.StatusBadRequest
 		resp.ErrorCode = ErrCodeBadRequest
 		resp.ErrorMessage = errValidator.ErrorMessage
 		resp.ErrorValidation = errValidator.Details
-		resp.ErrorValidation = errValidator.Details
+	case isGRPCStatusError(err):
+		return ParseErrorResponse(ParseGRPCError(err), acceptLanguage...)
+	default:
+		if code := jwtErrorCode(err); code != "" {
+			resp.StatusCode = http.StatusUnauthorized
+			resp.ErrorCode = code
+		}
+	}
+	if len(acceptLanguage) > 0 {
+		resp.ErrorMessage = localizeErrorMessage(resp.StatusCode, resp.ErrorCode, resp.ErrorMessage, acceptLanguage[0])
 	}
 	return resp
 }
@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error code and message of responses aggregating the errors of multiple items
+const (
+	ErrCodeMultipleErrors    = "MULTIPLE_ERRORS"
+	ErrMessageMultipleErrors = "Multiple errors occurred"
+)
+
+// MultiErrorItem is the error of an item of a batch operation, identified by index or key
+type MultiErrorItem struct {
+	Index int    // Index of the item, -1 for items identified by key
+	Key   string // Key of the item, e.g. an ID, empty for items identified by index
+	Err   error  // The error of the item
+}
+
+// MultiError collects the errors of independent items of a batch operation.
+// Like errors.Join, errors.Is and errors.As match the errors of any item.
+type MultiError struct {
+	Items []MultiErrorItem // The errors of the failed items
+}
+
+// ErrorItemResponse represents the error response of an item of a batch operation
+type ErrorItemResponse struct {
+	Index *int   `json:"index,omitempty" example:"2"` // Index of the item
+	Key   string `json:"key,omitempty"`               // Key of the item
+	ErrorResponse
+}
+
+// BatchResponse represents the result of a batch operation, with status 207 for partial success
+type BatchResponse struct {
+	StatusCode int                 `json:"statusCode" example:"207"` // HTTP status code
+	Total      int                 `json:"total" example:"3"`        // Number of items
+	Succeeded  int                 `json:"succeeded" example:"2"`    // Number of succeeded items
+	Failed     int                 `json:"failed" example:"1"`       // Number of failed items
+	Errors     []ErrorItemResponse `json:"errors,omitempty"`         // List of errors of the failed items
+}
+
+func init() {
+	RegisterError(ErrorDefinition{
+		Code:       ErrCodeMultipleErrors,
+		StatusCode: http.StatusBadRequest,
+		Message:    ErrMessageMultipleErrors,
+	})
+}
+
+// Error function for MultiErrorItem to return the error message prefixed with the index or key
+func (i MultiErrorItem) Error() string {
+	if i.Index < 0 {
+		return fmt.Sprintf("[%s]: %s", i.Key, i.Err)
+	}
+	return fmt.Sprintf("[%d]: %s", i.Index, i.Err)
+}
+
+// Unwrap returns the error of the item
+func (i MultiErrorItem) Unwrap() error {
+	return i.Err
+}
+
+// NewMultiError creates a MultiError from the errors of items by index, skipping nil errors
+func NewMultiError(errs ...error) MultiError {
+	multiErr := MultiError{}
+	for i, err := range errs {
+		multiErr.Add(i, err)
+	}
+	return multiErr
+}
+
+// Add adds the error of the item at the index, nil errors are ignored
+func (e *MultiError) Add(index int, err error) {
+	if err != nil {
+		e.Items = append(e.Items, MultiErrorItem{Index: index, Err: err})
+	}
+}
+
+// AddKey adds the error of the item with the key, nil errors are ignored
+func (e *MultiError) AddKey(key string, err error) {
+	if err != nil {
+		e.Items = append(e.Items, MultiErrorItem{Index: -1, Key: key, Err: err})
+	}
+}
+
+// Len returns the number of failed items
+func (e MultiError) Len() int {
+	return len(e.Items)
+}
+
+// ErrorOrNil returns the MultiError, or nil when no item failed
+func (e MultiError) ErrorOrNil() error {
+	if len(e.Items) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Error function for MultiError to return the error messages of the items, one per line
+func (e MultiError) Error() string {
+	messages := make([]string, len(e.Items))
+	for i, item := range e.Items {
+		messages[i] = item.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns the errors of the items
+func (e MultiError) Unwrap() []error {
+	errs := make([]error, len(e.Items))
+	for i, item := range e.Items {
+		errs[i] = item
+	}
+	return errs
+}
+
+// parseMultiErrorResponse converts a MultiError into an ErrorResponse with the error response
+// of each item. The status code is the status of the items when they are all the same,
+// otherwise 500 when any item failed with a server error or 400.
+func parseMultiErrorResponse(err MultiError, acceptLanguage []string) ErrorResponse {
+	resp := ErrorResponse{
+		StatusCode:   http.StatusBadRequest,
+		ErrorCode:    ErrCodeMultipleErrors,
+		ErrorMessage: ErrMessageMultipleErrors,
+		ErrorItems:   make([]ErrorItemResponse, len(err.Items)),
+	}
+	statusCodes := map[int]bool{}
+	for i, item := range err.Items {
+		itemResp := ErrorItemResponse{
+			Key:           item.Key,
+			ErrorResponse: ParseErrorResponse(item.Err, acceptLanguage...),
+		}
+		if item.Index >= 0 {
+			itemResp.Index = &item.Index
+		}
+		resp.ErrorItems[i] = itemResp
+		statusCodes[itemResp.StatusCode] = true
+		if itemResp.StatusCode >= http.StatusInternalServerError {
+			resp.StatusCode = http.StatusInternalServerError
+		}
+	}
+	if len(statusCodes) == 1 {
+		resp.StatusCode = resp.ErrorItems[0].StatusCode
+	}
+	return resp
+}
+
+// NewBatchResponse creates the response of a batch operation of total items from its error.
+// The status code is 200 when no item failed, 207 when some items failed, and the status
+// of the errors when all items failed. Errors other than MultiError fail all items.
+func NewBatchResponse(total int, err error, acceptLanguage ...string) BatchResponse {
+	resp := BatchResponse{
+		StatusCode: http.StatusOK,
+		Total:      total,
+		Succeeded:  total,
+	}
+	var multiErr MultiError
+	isMultiErr := errors.As(err, &multiErr)
+	if err == nil || isMultiErr && multiErr.Len() == 0 {
+		return resp
+	}
+	errResp := ParseErrorResponse(err, acceptLanguage...)
+	resp.Errors = errResp.ErrorItems
+	resp.Failed = len(errResp.ErrorItems)
+	if !isMultiErr {
+		resp.Errors = []ErrorItemResponse{{ErrorResponse: errResp}}
+		resp.Failed = total
+	}
+	resp.Succeeded = Max(0, total-resp.Failed)
+	resp.StatusCode = http.StatusMultiStatus
+	if resp.Succeeded == 0 {
+		resp.StatusCode = errResp.StatusCode
+	}
+	return resp
+}
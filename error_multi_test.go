@@ -0,0 +1,81 @@
+package utils_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+func TestMultiError(t *testing.T) {
+	errNotFound := errors.New("item not found")
+	multiErr := utils.NewMultiError(nil, utils.NewCommonErrorNotFound(errNotFound), nil)
+	multiErr.AddKey("sku-1", utils.NewCommonErrorConflict(errors.New("duplicate sku")))
+	multiErr.AddKey("sku-2", nil)
+	assert.Equal(t, 2, multiErr.Len())
+	assert.EqualError(t, multiErr, "[1]: item not found\n[sku-1]: duplicate sku")
+
+	err := fmt.Errorf("import: %w", multiErr.ErrorOrNil())
+	assert.ErrorIs(t, err, errNotFound)
+	var commonErr utils.CommonError
+	assert.ErrorAs(t, err, &commonErr)
+	assert.Equal(t, http.StatusNotFound, commonErr.StatusCode)
+	var target utils.MultiError
+	assert.ErrorAs(t, err, &target)
+	assert.NoError(t, utils.NewMultiError(nil, nil).ErrorOrNil())
+}
+
+func TestParseErrorResponse_MultiError(t *testing.T) {
+	multiErr := utils.NewMultiError(nil, utils.NewCommonErrorNotFound(nil), utils.ValidateStruct(Data{}))
+	resp := utils.ParseErrorResponse(multiErr)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, utils.ErrCodeMultipleErrors, resp.ErrorCode)
+	assert.Equal(t, utils.ErrMessageMultipleErrors, resp.ErrorMessage)
+	assert.Len(t, resp.ErrorItems, 2)
+	assert.Equal(t, 1, *resp.ErrorItems[0].Index)
+	assert.Equal(t, http.StatusNotFound, resp.ErrorItems[0].StatusCode)
+	assert.Equal(t, utils.ErrCodeNotFound, resp.ErrorItems[0].ErrorCode)
+	assert.Equal(t, 2, *resp.ErrorItems[1].Index)
+	assert.Equal(t, utils.ErrCodeBadRequest, resp.ErrorItems[1].ErrorCode)
+	assert.Len(t, resp.ErrorItems[1].ErrorValidation, 1)
+
+	multiErr = utils.MultiError{}
+	multiErr.AddKey("a", utils.NewCommonErrorConflict(nil))
+	multiErr.AddKey("b", utils.NewCommonErrorConflict(nil))
+	resp = utils.ParseErrorResponse(fmt.Errorf("batch: %w", multiErr))
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	assert.Nil(t, resp.ErrorItems[0].Index)
+	assert.Equal(t, "a", resp.ErrorItems[0].Key)
+
+	multiErr.AddKey("c", errors.New("database is down"))
+	resp = utils.ParseErrorResponse(multiErr)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestNewBatchResponse(t *testing.T) {
+	resp := utils.NewBatchResponse(3, nil)
+	assert.Equal(t, utils.BatchResponse{StatusCode: http.StatusOK, Total: 3, Succeeded: 3}, resp)
+
+	resp = utils.NewBatchResponse(3, utils.NewMultiError(nil, nil, nil))
+	assert.Equal(t, utils.BatchResponse{StatusCode: http.StatusOK, Total: 3, Succeeded: 3}, resp)
+
+	resp = utils.NewBatchResponse(3, utils.NewMultiError(nil, utils.NewCommonErrorNotFound(nil)))
+	assert.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+	assert.Equal(t, 2, resp.Succeeded)
+	assert.Equal(t, 1, resp.Failed)
+	assert.Len(t, resp.Errors, 1)
+
+	resp = utils.NewBatchResponse(2, utils.NewMultiError(utils.NewCommonErrorNotFound(nil), utils.NewCommonErrorNotFound(nil)))
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, 0, resp.Succeeded)
+
+	resp = utils.NewBatchResponse(2, utils.NewCommonErrorServiceUnavailable(nil))
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, 2, resp.Failed)
+	assert.Len(t, resp.Errors, 1)
+	assert.Nil(t, resp.Errors[0].Index)
+}
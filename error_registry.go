@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"net/http"
+	"sync"
+
+	"golang.org/x/text/language"
+)
+
+// ErrorDefinition describes a registered error code with its status and messages
+type ErrorDefinition struct {
+	Code         string            // The error code, e.g. ErrCodeNotFound
+	StatusCode   int               // The HTTP status code of the error
+	Message      string            // The default English message
+	Translations map[string]string // The messages by language tag, e.g. "th"
+}
+
+// errorRegistry holds the registered error codes and the default codes of HTTP statuses
+var errorRegistry = struct {
+	sync.RWMutex
+	codes    map[string]ErrorDefinition
+	statuses map[int]string
+}{
+	codes:    map[string]ErrorDefinition{},
+	statuses: map[int]string{},
+}
+
+func init() {
+	for _, def := range []ErrorDefinition{
+		{Code: ErrCodeBadRequest, StatusCode: http.StatusBadRequest, Message: ErrMessageBadRequest},
+		{Code: ErrCodeUnauthorized, StatusCode: http.StatusUnauthorized, Message: ErrMessageUnauthorized},
+		{Code: ErrCodeForbidden, StatusCode: http.StatusForbidden, Message: ErrMessageForbidden},
+		{Code: ErrCodeNotFound, StatusCode: http.StatusNotFound, Message: ErrMessageNotFound},
+		{Code: ErrCodeMethodNotAllowed, StatusCode: http.StatusMethodNotAllowed, Message: ErrMessageMethodNotAllowed},
+		{Code: ErrCodeRequestTimeout, StatusCode: http.StatusRequestTimeout, Message: ErrMessageRequestTimeout},
+		{Code: ErrCodeConflict, StatusCode: http.StatusConflict, Message: ErrMessageConflict},
+		{Code: ErrCodeGone, StatusCode: http.StatusGone, Message: ErrMessageGone},
+		{Code: ErrCodePayloadTooLarge, StatusCode: http.StatusRequestEntityTooLarge, Message: ErrMessagePayloadTooLarge},
+		{Code: ErrCodeUnsupportedMediaType, StatusCode: http.StatusUnsupportedMediaType, Message: ErrMessageUnsupportedMediaType},
+		{Code: ErrCodeUnprocessableEntity, StatusCode: http.StatusUnprocessableEntity, Message: ErrMessageUnprocessableEntity},
+		{Code: ErrCodeTooManyRequests, StatusCode: http.StatusTooManyRequests, Message: ErrMessageTooManyRequests},
+		{Code: ErrCodeNotImplemented, StatusCode: http.StatusNotImplemented, Message: ErrMessageNotImplemented},
+		{Code: ErrCodeBadGateway, StatusCode: http.StatusBadGateway, Message: ErrMessageBadGateway},
+		{Code: ErrCodeServiceUnavailable, StatusCode: http.StatusServiceUnavailable, Message: ErrMessageServiceUnavailable},
+		{Code: ErrCodeGatewayTimeout, StatusCode: http.StatusGatewayTimeout, Message: ErrMessageGatewayTimeout},
+	} {
+		RegisterError(def)
+		errorRegistry.statuses[def.StatusCode] = def.Code
+	}
+	RegisterError(ErrorDefinition{
+		Code:       ErrCodeSomethingWentWrong,
+		StatusCode: http.StatusInternalServerError,
+		Message:    ErrMessageSomethingWentWrong,
+	})
+}
+
+// RegisterError registers an error code, replacing any previous definition of the code.
+// Translations are keyed by BCP 47 language tags.
+func RegisterError(def ErrorDefinition) {
+	translations := make(map[string]string, len(def.Translations))
+	for lang, message := range def.Translations {
+		translations[normalizeLanguageTag(lang)] = message
+	}
+	def.Translations = translations
+	errorRegistry.Lock()
+	defer errorRegistry.Unlock()
+	errorRegistry.codes[def.Code] = def
+}
+
+// RegisterErrorTranslations adds the messages of registered error codes in a language.
+// Messages of unknown codes are ignored.
+func RegisterErrorTranslations(lang string, messages map[string]string) {
+	lang = normalizeLanguageTag(lang)
+	errorRegistry.Lock()
+	defer errorRegistry.Unlock()
+	for code, message := range messages {
+		if def, ok := errorRegistry.codes[code]; ok {
+			translations := make(map[string]string, len(def.Translations)+1)
+			for k, v := range def.Translations {
+				translations[k] = v
+			}
+			translations[lang] = message
+			def.Translations = translations
+			errorRegistry.codes[code] = def
+		}
+	}
+}
+
+// LookupError returns the definition of a registered error code
+func LookupError(code string) (ErrorDefinition, bool) {
+	errorRegistry.RLock()
+	defer errorRegistry.RUnlock()
+	def, ok := errorRegistry.codes[code]
+	return def, ok
+}
+
+// NewCommonError creates a new CommonError instance with the registered
+// status code of the error code, or 500 for unknown codes
+func NewCommonError(code string, err error) CommonError {
+	statusCode := http.StatusInternalServerError
+	if def, ok := LookupError(code); ok {
+		statusCode = def.StatusCode
+	}
+	return newCommonError(statusCode, code, err)
+}
+
+// statusErrorCode returns the default error code of an HTTP status
+func statusErrorCode(statusCode int) (string, bool) {
+	errorRegistry.RLock()
+	defer errorRegistry.RUnlock()
+	code, ok := errorRegistry.statuses[statusCode]
+	return code, ok
+}
+
+// errorMessage returns the default message of a registered error code
+func errorMessage(code string) (string, bool) {
+	def, ok := LookupError(code)
+	return def.Message, ok && def.Message != ""
+}
+
+// localizeErrorMessage translates the message into the best matching language of
+// the Accept-Language value when it is the default message of the error code
+// or of the status code. Other messages are returned as is.
+func localizeErrorMessage(statusCode int, code, message, acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return message
+	}
+	candidates := []string{code}
+	if statusDefault, ok := statusErrorCode(statusCode); ok {
+		candidates = append(candidates, statusDefault)
+	}
+	for _, candidate := range candidates {
+		def, ok := LookupError(candidate)
+		if !ok || def.Message != message {
+			continue
+		}
+		for _, tag := range tags {
+			if translation, ok := def.Translations[tag.String()]; ok {
+				return translation
+			}
+			base, _ := tag.Base()
+			if translation, ok := def.Translations[base.String()]; ok {
+				return translation
+			}
+		}
+		return message
+	}
+	return message
+}
+
+// normalizeLanguageTag returns the canonical form of a BCP 47 language tag
+func normalizeLanguageTag(lang string) string {
+	if tag, err := language.Parse(lang); err == nil {
+		return tag.String()
+	}
+	return lang
+}
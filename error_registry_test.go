@@ -0,0 +1,87 @@
+package utils_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+func TestRegisterError(t *testing.T) {
+	utils.RegisterError(utils.ErrorDefinition{
+		Code:         "INSUFFICIENT_BALANCE",
+		StatusCode:   http.StatusPaymentRequired,
+		Message:      "Insufficient balance",
+		Translations: map[string]string{"th": "ยอดเงินไม่เพียงพอ"},
+	})
+	def, ok := utils.LookupError("INSUFFICIENT_BALANCE")
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusPaymentRequired, def.StatusCode)
+
+	err := utils.NewCommonError("INSUFFICIENT_BALANCE", nil)
+	assert.Equal(t, http.StatusPaymentRequired, err.StatusCode)
+	resp := utils.ParseErrorResponse(err)
+	assert.Equal(t, "Insufficient balance", resp.ErrorMessage)
+	resp = utils.ParseErrorResponse(err, "th-TH,th;q=0.9,en;q=0.8")
+	assert.Equal(t, "ยอดเงินไม่เพียงพอ", resp.ErrorMessage)
+	resp = utils.ParseErrorResponse(err, "fr")
+	assert.Equal(t, "Insufficient balance", resp.ErrorMessage)
+
+	custom := utils.NewCommonError("INSUFFICIENT_BALANCE", errors.New("balance is 0"))
+	assert.Equal(t, "balance is 0", utils.ParseErrorResponse(custom, "th").ErrorMessage)
+
+	unknown := utils.NewCommonError("UNKNOWN_CODE", nil)
+	assert.Equal(t, http.StatusInternalServerError, unknown.StatusCode)
+}
+
+func TestRegisterErrorTranslations(t *testing.T) {
+	utils.RegisterErrorTranslations("th", map[string]string{
+		utils.ErrCodeNotFound:     "ไม่พบข้อมูล",
+		utils.ErrCodeUnauthorized: "ไม่ได้รับอนุญาต",
+		"UNKNOWN_CODE":            "ignored",
+	})
+	resp := utils.ParseErrorResponse(echo.ErrNotFound, "th")
+	assert.Equal(t, utils.ErrCodeNotFound, resp.ErrorCode)
+	assert.Equal(t, "ไม่พบข้อมูล", resp.ErrorMessage)
+	resp = utils.ParseErrorResponse(echo.ErrNotFound, "en-US")
+	assert.Equal(t, utils.ErrMessageNotFound, resp.ErrorMessage)
+	resp = utils.ParseErrorResponse(echo.ErrNotFound, "invalid;;")
+	assert.Equal(t, utils.ErrMessageNotFound, resp.ErrorMessage)
+
+	unauthorized := echo.NewHTTPError(http.StatusUnauthorized).SetInternal(utils.ErrJWTRevoked)
+	resp = utils.ParseErrorResponse(unauthorized, "th")
+	assert.Equal(t, utils.ErrCodeUnauthorizedTokenRevoked, resp.ErrorCode)
+	assert.Equal(t, "ไม่ได้รับอนุญาต", resp.ErrorMessage)
+	_, ok := utils.LookupError("UNKNOWN_CODE")
+	assert.False(t, ok)
+}
+
+func TestParseErrorResponse_CommonStatuses(t *testing.T) {
+	tests := []struct {
+		err     utils.CommonError
+		status  int
+		code    string
+		message string
+	}{
+		{utils.NewCommonErrorConflict(nil), http.StatusConflict, utils.ErrCodeConflict, utils.ErrMessageConflict},
+		{utils.NewCommonErrorUnprocessableEntity(nil), http.StatusUnprocessableEntity, utils.ErrCodeUnprocessableEntity, utils.ErrMessageUnprocessableEntity},
+		{utils.NewCommonErrorTooManyRequests(nil), http.StatusTooManyRequests, utils.ErrCodeTooManyRequests, utils.ErrMessageTooManyRequests},
+		{utils.NewCommonErrorServiceUnavailable(nil), http.StatusServiceUnavailable, utils.ErrCodeServiceUnavailable, utils.ErrMessageServiceUnavailable},
+		{utils.NewCommonErrorPayloadTooLarge(nil), http.StatusRequestEntityTooLarge, utils.ErrCodePayloadTooLarge, utils.ErrMessagePayloadTooLarge},
+		{utils.NewCommonErrorNotFound(errors.New("user not found")), http.StatusNotFound, utils.ErrCodeNotFound, "user not found"},
+	}
+	for _, test := range tests {
+		resp := utils.ParseErrorResponse(test.err)
+		assert.Equal(t, test.status, resp.StatusCode)
+		assert.Equal(t, test.code, resp.ErrorCode)
+		assert.Equal(t, test.message, resp.ErrorMessage)
+	}
+
+	resp := utils.ParseErrorResponse(echo.NewHTTPError(http.StatusTooManyRequests))
+	assert.Equal(t, utils.ErrCodeTooManyRequests, resp.ErrorCode)
+	assert.Equal(t, utils.ErrMessageTooManyRequests, resp.ErrorMessage)
+}
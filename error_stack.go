@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sync/atomic"
+)
+
+// errorStackDepth is the maximum number of frames captured for an error
+const errorStackDepth = 32
+
+// errorStackEnabled reports whether CommonError constructors capture the call stack
+var errorStackEnabled atomic.Bool
+
+// errorStack is the call stack captured when an error was created
+type errorStack []uintptr
+
+// SetErrorStackEnabled enables or disables capturing the call stack when a CommonError
+// is created by its constructors. The stack is printed with the %+v verb and never
+// included in API responses. Capturing is disabled by default.
+func SetErrorStackEnabled(enabled bool) {
+	errorStackEnabled.Store(enabled)
+}
+
+// captureErrorStack returns the call stack of the caller of the CommonError constructor,
+// or nil when stack capture is disabled
+func captureErrorStack() *errorStack {
+	if !errorStackEnabled.Load() {
+		return nil
+	}
+	pcs := make([]uintptr, errorStackDepth)
+	// Skip runtime.Callers, captureErrorStack, newCommonError and the constructor
+	n := runtime.Callers(4, pcs)
+	stack := errorStack(pcs[:n])
+	return &stack
+}
+
+// frames returns the frames of the call stack
+func (s errorStack) frames() []runtime.Frame {
+	result := make([]runtime.Frame, 0, len(s))
+	if len(s) == 0 {
+		return result
+	}
+	frames := runtime.CallersFrames(s)
+	for {
+		frame, more := frames.Next()
+		result = append(result, frame)
+		if !more {
+			break
+		}
+	}
+	return result
+}
+
+// writeTo writes the call stack with one function and file:line pair per frame
+func (s errorStack) writeTo(w io.Writer) {
+	for _, frame := range s.frames() {
+		fmt.Fprintf(w, "\n%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+	}
+}
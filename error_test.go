@@ -3,7 +3,9 @@ package utils_test
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/go-playground/validator/v10"
@@ -129,8 +131,9 @@ func TestParseValidationError(t *testing.T) {
 			Details: []utils.ValidationErrorDetail{
 				{
 					Field:   "Balance",
+					Path:    "Balance",
 					Tag:     "number_string",
-					Message: "Key: 'Data.Balance', Error: Validation for 'Balance' failed on the 'number_string' tag",
+					Message: "Balance must be a valid number",
 				},
 			},
 			Errors: err.(validator.ValidationErrors),
@@ -255,6 +258,17 @@ func TestParseErrorResponse(t *testing.T) {
 		assert.Equal(t, expected, resp.ErrorMessage)
 	})
 
+	t.Run("HTTPErrorCustomMessage", func(t *testing.T) {
+		resp := utils.ParseErrorResponse(echo.NewHTTPError(http.StatusConflict, "email already registered"))
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		assert.Equal(t, utils.ErrCodeConflict, resp.ErrorCode)
+		assert.Equal(t, "email already registered", resp.ErrorMessage)
+
+		resp = utils.ParseErrorResponse(echo.ErrServiceUnavailable)
+		assert.Equal(t, utils.ErrCodeServiceUnavailable, resp.ErrorCode)
+		assert.Equal(t, utils.ErrMessageServiceUnavailable, resp.ErrorMessage)
+	})
+
 	t.Run("CommonErrorWithValidationError", func(t *testing.T) {
 		data := Data{Balance: "foo"}
 		errValidation := utils.ValidateStruct(data)
@@ -297,6 +311,64 @@ func TestParseErrorResponse(t *testing.T) {
 	})
 }
 
+func TestCommonError_Wrapped(t *testing.T) {
+	errNotFound := errors.New("user not found")
+	commonErr := utils.NewCommonErrorNotFound(errNotFound)
+	wrapped := fmt.Errorf("get user: %w", commonErr)
+	assert.True(t, utils.IsCommonError(wrapped))
+	assert.ErrorIs(t, wrapped, errNotFound)
+	assert.Equal(t, commonErr, utils.ParseCommonError(wrapped))
+
+	resp := utils.ParseErrorResponse(wrapped)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, utils.ErrCodeNotFound, resp.ErrorCode)
+	assert.Equal(t, "user not found", resp.ErrorMessage)
+
+	resp = utils.ParseErrorResponse(fmt.Errorf("handler: %w", echo.ErrForbidden))
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, utils.ErrCodeForbidden, resp.ErrorCode)
+}
+
+func TestValidationError_Wrapped(t *testing.T) {
+	errValidation := utils.ValidateStruct(Data{Balance: "foo"})
+	assert.True(t, utils.IsValidationError(fmt.Errorf("create: %w", errValidation)))
+	var validationErrs validator.ValidationErrors
+	assert.ErrorAs(t, errValidation, &validationErrs)
+
+	wrapped := fmt.Errorf("create: %w", utils.NewCommonErrorBadRequest(fmt.Errorf("input: %w", errValidation)))
+	resp := utils.ParseErrorResponse(wrapped)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "Validation failed for 'Balance'", resp.ErrorMessage)
+	assert.Len(t, resp.ErrorValidation, 1)
+	assert.Nil(t, utils.ValidationError{ErrorMessage: "invalid"}.Unwrap())
+}
+
+func TestCommonError_Format(t *testing.T) {
+	err := utils.NewCommonErrorConflict(errors.New("duplicate email"))
+	assert.Nil(t, err.StackTrace())
+	assert.Equal(t, "duplicate email", fmt.Sprintf("%+v", err))
+
+	utils.SetErrorStackEnabled(true)
+	defer utils.SetErrorStackEnabled(false)
+	err = utils.NewCommonErrorConflict(errors.New("duplicate email"))
+	frames := err.StackTrace()
+	assert.NotEmpty(t, frames)
+	assert.True(t, strings.HasSuffix(frames[0].Function, "TestCommonError_Format"))
+	assert.Equal(t, "duplicate email", fmt.Sprintf("%v", err))
+	assert.Equal(t, `"duplicate email"`, fmt.Sprintf("%q", err))
+	detailed := fmt.Sprintf("%+v", err)
+	assert.True(t, strings.HasPrefix(detailed, "duplicate email\n"))
+	assert.Contains(t, detailed, "error_test.go:")
+
+	resp := utils.ParseErrorResponse(err)
+	assert.Equal(t, "duplicate email", resp.ErrorMessage)
+	b, _ := json.Marshal(err)
+	assert.Equal(t, `{"statusCode":409,"errorCode":"CONFLICT","errorMessage":"duplicate email"}`, string(b))
+
+	registered := utils.NewCommonError(utils.ErrCodeGone, nil)
+	assert.True(t, strings.HasSuffix(registered.StackTrace()[0].Function, "TestCommonError_Format"))
+}
+
 func BenchmarkParseValidationError(b *testing.B) {
 	data := Data{Balance: "foo"}
 	err := utils.Validate.Struct(data)
@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// grpcStatusCodeMetadataKey is the ErrorInfo metadata key holding the HTTP status code,
+// so that statuses sharing a gRPC code map back to the original HTTP status
+const grpcStatusCodeMetadataKey = "statusCode"
+
+// grpcAcceptLanguageKey is the metadata key of the language of error messages
+const grpcAcceptLanguageKey = "accept-language"
+
+// httpStatusGRPCCodes maps HTTP status codes to gRPC codes
+var httpStatusGRPCCodes = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusMethodNotAllowed:      codes.Unimplemented,
+	http.StatusRequestTimeout:        codes.DeadlineExceeded,
+	http.StatusConflict:              codes.AlreadyExists,
+	http.StatusGone:                  codes.NotFound,
+	http.StatusPreconditionFailed:    codes.FailedPrecondition,
+	http.StatusRequestEntityTooLarge: codes.ResourceExhausted,
+	http.StatusUnsupportedMediaType:  codes.InvalidArgument,
+	http.StatusUnprocessableEntity:   codes.InvalidArgument,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+	499:                              codes.Canceled, // Client Closed Request
+	http.StatusInternalServerError:   codes.Internal,
+	http.StatusNotImplemented:        codes.Unimplemented,
+	http.StatusBadGateway:            codes.Unavailable,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+	http.StatusGatewayTimeout:        codes.DeadlineExceeded,
+}
+
+// grpcCodeHTTPStatuses maps gRPC codes to HTTP status codes
+var grpcCodeHTTPStatuses = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           499,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+// GRPCCode returns the gRPC code of an HTTP status code
+func GRPCCode(statusCode int) codes.Code {
+	if code, ok := httpStatusGRPCCodes[statusCode]; ok {
+		return code
+	}
+	if statusCode >= http.StatusInternalServerError {
+		return codes.Internal
+	}
+	return codes.Unknown
+}
+
+// HTTPStatusCode returns the HTTP status code of a gRPC code
+func HTTPStatusCode(code codes.Code) int {
+	if statusCode, ok := grpcCodeHTTPStatuses[code]; ok {
+		return statusCode
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPCStatus converts the error response into a gRPC status with an ErrorInfo detail
+// holding the error code and HTTP status code, and a BadRequest detail with a field
+// violation for each validation error
+func (e ErrorResponse) GRPCStatus() *status.Status {
+	st := status.New(GRPCCode(e.StatusCode), e.ErrorMessage)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason:   e.ErrorCode,
+		Metadata: map[string]string{grpcStatusCodeMetadataKey: strconv.Itoa(e.StatusCode)},
+	}}
+	if len(e.ErrorValidation) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, detail := range e.ErrorValidation {
+			field := detail.Path
+			if field == "" {
+				field = detail.Field
+			}
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field,
+				Description: detail.Message,
+			})
+		}
+		details = append(details, badRequest)
+	}
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// GRPCStatus converts the error into a gRPC status using ParseErrorResponse,
+// allowing CommonError to be returned from gRPC handlers
+func (e CommonError) GRPCStatus() *status.Status {
+	return ParseErrorResponse(e).GRPCStatus()
+}
+
+// NewGRPCStatus converts an error into a gRPC status. Errors carrying a gRPC status,
+// e.g. from gRPC clients, keep their status, other errors are converted using
+// ParseErrorResponse with the messages localized to the optional Accept-Language value.
+func NewGRPCStatus(err error, acceptLanguage ...string) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	if isGRPCStatusError(err) && !IsCommonError(err) {
+		st, _ := status.FromError(err)
+		return st
+	}
+	return ParseErrorResponse(err, acceptLanguage...).GRPCStatus()
+}
+
+// ParseGRPCError converts an error returned by a gRPC client into a CommonError, restoring the
+// error code and HTTP status code from the ErrorInfo detail and the validation errors from
+// the BadRequest detail. Errors without a gRPC status are converted using ParseCommonError.
+func ParseGRPCError(err error) CommonError {
+	var commonErr CommonError
+	if errors.As(err, &commonErr) {
+		return commonErr
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return ParseCommonError(err)
+	}
+	problem := ProblemDetails{
+		Status: HTTPStatusCode(st.Code()),
+		Detail: st.Message(),
+	}
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			problem.ErrorCode = detail.GetReason()
+			if statusCode, err := strconv.Atoi(detail.GetMetadata()[grpcStatusCodeMetadataKey]); err == nil {
+				problem.Status = statusCode
+			}
+		case *errdetails.BadRequest:
+			for _, violation := range detail.GetFieldViolations() {
+				problem.Errors = append(problem.Errors, ValidationErrorDetail{
+					Field:   violation.GetField(),
+					Path:    violation.GetField(),
+					Message: violation.GetDescription(),
+				})
+			}
+		}
+	}
+	return problem.CommonError()
+}
+
+// isGRPCStatusError reports whether the error is or wraps an error carrying a gRPC status
+func isGRPCStatusError(err error) bool {
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	return errors.As(err, &grpcErr)
+}
+
+// GRPCUnaryServerInterceptor returns a unary server interceptor converting the errors
+// returned by handlers into gRPC statuses using NewGRPCStatus, localizing the messages
+// to the accept-language metadata of the request
+func GRPCUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, NewGRPCStatus(err, grpcAcceptLanguage(ctx)...).Err()
+		}
+		return resp, nil
+	}
+}
+
+// GRPCStreamServerInterceptor returns a stream server interceptor converting the errors
+// returned by handlers into gRPC statuses using NewGRPCStatus, localizing the messages
+// to the accept-language metadata of the request
+func GRPCStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return NewGRPCStatus(err, grpcAcceptLanguage(ss.Context())...).Err()
+		}
+		return nil
+	}
+}
+
+// grpcAcceptLanguage returns the accept-language metadata of the incoming request
+func grpcAcceptLanguage(ctx context.Context) []string {
+	return metadata.ValueFromIncomingContext(ctx, grpcAcceptLanguageKey)
+}
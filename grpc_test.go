@@ -0,0 +1,111 @@
+package utils_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+type mockServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s mockServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestGRPCCode(t *testing.T) {
+	assert.Equal(t, codes.InvalidArgument, utils.GRPCCode(http.StatusUnprocessableEntity))
+	assert.Equal(t, codes.Unauthenticated, utils.GRPCCode(http.StatusUnauthorized))
+	assert.Equal(t, codes.Internal, utils.GRPCCode(http.StatusHTTPVersionNotSupported))
+	assert.Equal(t, codes.Unknown, utils.GRPCCode(http.StatusTeapot))
+	assert.Equal(t, http.StatusNotFound, utils.HTTPStatusCode(codes.NotFound))
+	assert.Equal(t, http.StatusServiceUnavailable, utils.HTTPStatusCode(codes.Unavailable))
+	assert.Equal(t, http.StatusInternalServerError, utils.HTTPStatusCode(codes.Code(99)))
+}
+
+func TestNewGRPCStatus(t *testing.T) {
+	st := utils.NewGRPCStatus(utils.NewCommonErrorNotFound(errors.New("user not found")))
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "user not found", st.Message())
+	info := st.Details()[0].(*errdetails.ErrorInfo)
+	assert.Equal(t, utils.ErrCodeNotFound, info.GetReason())
+
+	st = utils.NewGRPCStatus(fmt.Errorf("create: %w", utils.ValidateStruct(Data{})), "th")
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	badRequest := st.Details()[1].(*errdetails.BadRequest)
+	assert.Equal(t, "Balance", badRequest.GetFieldViolations()[0].GetField())
+	assert.Equal(t, "Balance จำเป็นต้องระบุ", badRequest.GetFieldViolations()[0].GetDescription())
+
+	downstream := status.Error(codes.Aborted, "transaction aborted")
+	st = utils.NewGRPCStatus(fmt.Errorf("call: %w", downstream))
+	assert.Equal(t, codes.Aborted, st.Code())
+	assert.Equal(t, codes.OK, utils.NewGRPCStatus(nil).Code())
+
+	st, ok := status.FromError(utils.NewCommonErrorTooManyRequests(nil))
+	assert.True(t, ok)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+}
+
+func TestParseGRPCError(t *testing.T) {
+	err := utils.NewGRPCStatus(utils.NewCommonErrorUnprocessableEntity(errors.New("invalid state"))).Err()
+	commonErr := utils.ParseGRPCError(err)
+	assert.Equal(t, http.StatusUnprocessableEntity, commonErr.StatusCode)
+	assert.Equal(t, utils.ErrCodeUnprocessableEntity, commonErr.ErrorCode)
+	assert.EqualError(t, commonErr, "invalid state")
+
+	err = utils.NewGRPCStatus(utils.ValidateStruct(Data{Balance: "x"})).Err()
+	resp := utils.ParseErrorResponse(err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, utils.ErrCodeBadRequest, resp.ErrorCode)
+	assert.Equal(t, "Balance", resp.ErrorValidation[0].Path)
+	assert.Equal(t, "Balance must be a valid number", resp.ErrorValidation[0].Message)
+
+	commonErr = utils.ParseGRPCError(status.Error(codes.Unavailable, "try again"))
+	assert.Equal(t, http.StatusServiceUnavailable, commonErr.StatusCode)
+	assert.Equal(t, utils.ErrCodeServiceUnavailable, commonErr.ErrorCode)
+
+	original := utils.NewCommonErrorGone(nil)
+	assert.Equal(t, original, utils.ParseGRPCError(original))
+	commonErr = utils.ParseGRPCError(errors.New("boom"))
+	assert.Equal(t, utils.ErrCodeSomethingWentWrong, commonErr.ErrorCode)
+}
+
+func TestGRPCServerInterceptors(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-language", "th"))
+	unary := utils.GRPCUnaryServerInterceptor()
+	_, err := unary(ctx, nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+		return nil, utils.ValidateStruct(Data{})
+	})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, "Balance จำเป็นต้องระบุ", st.Details()[1].(*errdetails.BadRequest).GetFieldViolations()[0].GetDescription())
+
+	resp, err := unary(ctx, nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	stream := utils.GRPCStreamServerInterceptor()
+	err = stream(nil, mockServerStream{ctx: ctx}, &grpc.StreamServerInfo{}, func(any, grpc.ServerStream) error {
+		return utils.NewCommonErrorForbidden(nil)
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	err = stream(nil, mockServerStream{ctx: ctx}, &grpc.StreamServerInfo{}, func(any, grpc.ServerStream) error {
+		return nil
+	})
+	assert.NoError(t, err)
+}
@@ -6,18 +6,24 @@ import "encoding/json"
 var JSON JsonUtil
 
 // JsonUtil is a struct with methods for parsing and validating JSON data.
-type JsonUtil struct{}
+type JsonUtil struct {
+	Validator *Validator // The validator of the parsed data, defaults to DefaultValidator
+}
 
-// ParseAndValidate parses and validates JSON data into the given result struct.
+// ParseAndValidate parses, sanitizes and validates JSON data into the given result struct using the Validator.
 // If the result is an array or slice, it is validated as a list of items.
-func (JsonUtil) ParseAndValidate(data string, result any) error {
+func (j JsonUtil) ParseAndValidate(data string, result any) error {
 	if err := json.Unmarshal([]byte(data), result); err != nil {
 		return err
 	}
+	v := validatorOrDefault(j.Validator)
+	if err := v.Sanitize(result); err != nil {
+		return err
+	}
 	if IsArrayOrSlice(result) {
-		return ValidateStruct(Result{List: result})
+		return v.Struct(Result{List: result})
 	}
-	return ValidateStruct(result)
+	return v.Struct(result)
 }
 
 // Parse parses JSON data into the given result struct.
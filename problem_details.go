@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// MIMEApplicationProblemJSON is the media type of RFC 7807 Problem Details
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// DefaultProblemType is the problem type URI when the problem has no additional semantics
+const DefaultProblemType = "about:blank"
+
+// problemDetailsMaxBodySize is the maximum size of a problem response body read by ParseProblemResponse
+const problemDetailsMaxBodySize = 1 << 20
+
+// problemDetailsMembers are the JSON members of ProblemDetails that are not extensions
+var problemDetailsMembers = []string{"type", "title", "status", "detail", "instance", "errorCode", "errors"}
+
+// ProblemDetails represents an RFC 7807 Problem Details object with the errorCode
+// and validation errors extension members
+type ProblemDetails struct {
+	Type       string                  `json:"type,omitempty" example:"about:blank"`         // URI reference identifying the problem type
+	Title      string                  `json:"title,omitempty" example:"Bad Request"`        // Short summary of the problem type
+	Status     int                     `json:"status,omitempty" example:"400"`               // HTTP status code
+	Detail     string                  `json:"detail,omitempty" example:"Validation failed"` // Explanation specific to this occurrence
+	Instance   string                  `json:"instance,omitempty" example:"/orders"`         // URI reference identifying this occurrence
+	ErrorCode  string                  `json:"errorCode,omitempty" example:"BAD_REQUEST"`    // Specific error code
+	Errors     []ValidationErrorDetail `json:"errors,omitempty"`                             // List of validation errors
+	Extensions map[string]any          `json:"-"`                                            // Other extension members
+}
+
+// MarshalJSON function for ProblemDetails to marshal the problem as JSON,
+// with the extension members at the top level
+func (p ProblemDetails) MarshalJSON() ([]byte, error) {
+	type Alias ProblemDetails
+	b, err := json.Marshal(Alias(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return b, err
+	}
+	members := map[string]any{}
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+	if err := json.Unmarshal(b, &members); err != nil {
+		return nil, err
+	}
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON function for ProblemDetails to unmarshal the problem from JSON,
+// collecting unknown members into Extensions
+func (p *ProblemDetails) UnmarshalJSON(data []byte) error {
+	type Alias ProblemDetails
+	aux := (*Alias)(p)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	members := map[string]any{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	for _, member := range problemDetailsMembers {
+		delete(members, member)
+	}
+	p.Extensions = nil
+	if len(members) > 0 {
+		p.Extensions = members
+	}
+	return nil
+}
+
+// ProblemDetails converts the error response into a ProblemDetails with the instance URI
+func (e ErrorResponse) ProblemDetails(instance string) ProblemDetails {
+	return ProblemDetails{
+		Type:      DefaultProblemType,
+		Title:     http.StatusText(e.StatusCode),
+		Status:    e.StatusCode,
+		Detail:    e.ErrorMessage,
+		Instance:  instance,
+		ErrorCode: e.ErrorCode,
+		Errors:    e.ErrorValidation,
+	}
+}
+
+// NewProblemDetails converts an error into a ProblemDetails using ParseErrorResponse
+func NewProblemDetails(err error, instance string, acceptLanguage ...string) ProblemDetails {
+	return ParseErrorResponse(err, acceptLanguage...).ProblemDetails(instance)
+}
+
+// CommonError converts the problem into a CommonError. The error code defaults to the code
+// of the status, and problems with validation errors carry a ValidationError instance.
+func (p ProblemDetails) CommonError() CommonError {
+	statusCode := p.Status
+	if statusCode == 0 {
+		statusCode = http.StatusInternalServerError
+	}
+	code := p.ErrorCode
+	if code == "" {
+		code = ErrCodeSomethingWentWrong
+		if statusDefault, ok := statusErrorCode(statusCode); ok {
+			code = statusDefault
+		}
+	}
+	message := p.Detail
+	if message == "" {
+		message = p.Title
+	}
+	var err error
+	switch {
+	case len(p.Errors) > 0:
+		err = ValidationError{ErrorMessage: message, Details: p.Errors}
+	case message != "":
+		err = errors.New(message)
+	}
+	return newCommonError(statusCode, code, err)
+}
+
+// ParseProblemResponse converts an error response of a remote API into a CommonError.
+// Problem Details and JSON bodies are decoded as ProblemDetails, JSON bodies in the
+// ErrorResponse format are also supported, and other bodies are used as the error message.
+// The response body is read but not closed.
+func ParseProblemResponse(resp *http.Response) CommonError {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, problemDetailsMaxBodySize))
+	if err != nil {
+		return ProblemDetails{Status: resp.StatusCode, Detail: err.Error()}.CommonError()
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == MIMEApplicationProblemJSON || mediaType == "application/json" {
+		errResp := ErrorResponse{}
+		if json.Unmarshal(body, &errResp) == nil && errResp.StatusCode != 0 {
+			return errResp.ProblemDetails("").CommonError()
+		}
+		problem := ProblemDetails{}
+		if err := json.Unmarshal(body, &problem); err == nil {
+			if problem.Status == 0 {
+				problem.Status = resp.StatusCode
+			}
+			return problem.CommonError()
+		}
+	}
+	detail := strings.TrimSpace(string(body))
+	if detail == "" {
+		detail = http.StatusText(resp.StatusCode)
+	}
+	return ProblemDetails{Status: resp.StatusCode, Detail: detail}.CommonError()
+}
@@ -0,0 +1,103 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+func newProblemResponse(statusCode int, contentType, body string) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{"Content-Type": []string{contentType}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestProblemDetails_JSON(t *testing.T) {
+	problem := utils.NewProblemDetails(utils.ValidateStruct(Data{}), "/accounts")
+	assert.Equal(t, utils.DefaultProblemType, problem.Type)
+	assert.Equal(t, "Bad Request", problem.Title)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, "Validation failed for 'Balance'", problem.Detail)
+	assert.Equal(t, "/accounts", problem.Instance)
+	assert.Equal(t, utils.ErrCodeBadRequest, problem.ErrorCode)
+	assert.Len(t, problem.Errors, 1)
+
+	problem.Extensions = map[string]any{"traceId": "abc", "status": 999}
+	b, err := json.Marshal(problem)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"traceId":"abc"`)
+	assert.Contains(t, string(b), `"status":400`)
+
+	decoded := utils.ProblemDetails{}
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	problem.Extensions = map[string]any{"traceId": "abc"}
+	assert.Equal(t, problem, decoded)
+
+	b, _ = json.Marshal(utils.ProblemDetails{Status: http.StatusNotFound})
+	assert.Equal(t, `{"status":404}`, string(b))
+	assert.Error(t, json.Unmarshal([]byte(`{"status":"404"}`), &decoded))
+}
+
+func TestProblemDetails_CommonError(t *testing.T) {
+	commonErr := utils.ProblemDetails{Status: http.StatusConflict, Title: "Conflict"}.CommonError()
+	assert.Equal(t, http.StatusConflict, commonErr.StatusCode)
+	assert.Equal(t, utils.ErrCodeConflict, commonErr.ErrorCode)
+	assert.EqualError(t, commonErr, "Conflict")
+
+	commonErr = utils.ProblemDetails{Status: http.StatusTeapot, ErrorCode: "TEAPOT"}.CommonError()
+	assert.Equal(t, "TEAPOT", commonErr.ErrorCode)
+	assert.Nil(t, commonErr.ErrorInstance)
+	assert.Equal(t, http.StatusInternalServerError, utils.ProblemDetails{}.CommonError().StatusCode)
+
+	problem := utils.NewProblemDetails(utils.ValidateStruct(Data{}), "")
+	resp := utils.ParseErrorResponse(problem.CommonError())
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, problem.Errors, resp.ErrorValidation)
+	assert.True(t, utils.IsValidationError(problem.CommonError()))
+}
+
+func TestParseProblemResponse(t *testing.T) {
+	body := `{"type":"https://example.com/insufficient-funds","title":"Forbidden","status":403,"detail":"Balance is 0","errorCode":"INSUFFICIENT_FUNDS"}`
+	commonErr := utils.ParseProblemResponse(newProblemResponse(http.StatusForbidden, "application/problem+json; charset=utf-8", body))
+	assert.Equal(t, http.StatusForbidden, commonErr.StatusCode)
+	assert.Equal(t, "INSUFFICIENT_FUNDS", commonErr.ErrorCode)
+	assert.EqualError(t, commonErr, "Balance is 0")
+
+	body = `{"statusCode":404,"errorCode":"NOT_FOUND","errorMessage":"User not found"}`
+	commonErr = utils.ParseProblemResponse(newProblemResponse(http.StatusNotFound, "application/json", body))
+	assert.Equal(t, http.StatusNotFound, commonErr.StatusCode)
+	assert.Equal(t, utils.ErrCodeNotFound, commonErr.ErrorCode)
+	assert.EqualError(t, commonErr, "User not found")
+
+	commonErr = utils.ParseProblemResponse(newProblemResponse(http.StatusBadGateway, "text/plain", " upstream failed\n"))
+	assert.Equal(t, utils.ErrCodeBadGateway, commonErr.ErrorCode)
+	assert.EqualError(t, commonErr, "upstream failed")
+
+	commonErr = utils.ParseProblemResponse(newProblemResponse(http.StatusServiceUnavailable, "application/problem+json", "{invalid"))
+	assert.Equal(t, utils.ErrCodeServiceUnavailable, commonErr.ErrorCode)
+	assert.EqualError(t, commonErr, "{invalid")
+
+	commonErr = utils.ParseProblemResponse(newProblemResponse(http.StatusGatewayTimeout, "", ""))
+	assert.EqualError(t, commonErr, "Gateway Timeout")
+
+	resp := newProblemResponse(http.StatusBadGateway, "", "")
+	resp.Body = io.NopCloser(errReader{})
+	commonErr = utils.ParseProblemResponse(resp)
+	assert.Equal(t, http.StatusBadGateway, commonErr.StatusCode)
+	assert.EqualError(t, commonErr, "read failed")
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -14,6 +15,9 @@ var Redis RedisUtil
 // RedisUtil is a utility struct for working with Redis
 type RedisUtil struct{}
 
+// redisPatternReplacer escapes the special characters of Redis glob patterns
+var redisPatternReplacer = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
 // redisPingTTL is the timeout duration for a Ping request to Redis
 var redisPingTTL = 5 * time.Second
 
@@ -82,3 +86,8 @@ func (r *RedisClient) JSONSet(key string, value any, expiration time.Duration) e
 func (r *RedisClient) JSONGet(key string, result any) error {
 	return r.GetStruct(key, result)
 }
+
+// escapeRedisPattern escapes a string to match literally in Redis glob patterns, e.g. of SCAN
+func escapeRedisPattern(s string) string {
+	return redisPatternReplacer.Replace(s)
+}
@@ -21,6 +21,16 @@ func createMockRedisServer(t *testing.T) (*miniredis.Miniredis, string) {
 	return s, fmt.Sprintf("redis://%s", s.Addr())
 }
 
+func newTestRedisClient(t *testing.T) (*miniredis.Miniredis, *utils.RedisClient) {
+	s, url := createMockRedisServer(t)
+	t.Cleanup(s.Close)
+	client, err := utils.Redis.New(utils.RedisConfig{URL: url})
+	if err != nil {
+		t.Fatalf("error creating Redis client: %v", err)
+	}
+	return s, client
+}
+
 func TestRedisNew(t *testing.T) {
 	s, url := createMockRedisServer(t)
 	defer s.Close()
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// SanitizeTag is the struct tag listing the sanitizers of a string field, applied in order
+// before validation, e.g. `mod:"trim,collapse_spaces,lower"`
+const SanitizeTag = "mod"
+
+// ErrSanitizerUnknown is returned when a SanitizeTag lists a sanitizer that is not registered
+var ErrSanitizerUnknown = errors.New("unknown sanitizer")
+
+// SanitizeFunc transforms the value of a string field
+type SanitizeFunc func(s string) string
+
+// defaultSanitizers are the sanitizers registered by NewValidator
+var defaultSanitizers = map[string]SanitizeFunc{
+	"trim":             strings.TrimSpace,
+	"ltrim":            func(s string) string { return strings.TrimLeft(s, " \t\r\n") },
+	"rtrim":            func(s string) string { return strings.TrimRight(s, " \t\r\n") },
+	"collapse_spaces":  String.RemoveDuplicateSpaces,
+	"remove_spaces":    String.RemoveAllSpaces,
+	"strip_zero_width": zeroWithReplacer.Replace,
+	"lower":            strings.ToLower,
+	"upper":            strings.ToUpper,
+	"title":            func(s string) string { return cases.Title(language.Und).String(s) },
+}
+
+// RegisterSanitizer registers a sanitizer usable in the SanitizeTag, replacing a previous sanitizer
+// of the name. It is not safe for concurrent use with sanitization and should be called at startup.
+func (v *Validator) RegisterSanitizer(name string, fn SanitizeFunc) {
+	v.sanitizerFuncs[name] = fn
+}
+
+// Sanitize applies the sanitizers of the SanitizeTag of the string fields of a struct,
+// recursing into nested structs, pointers, slices, arrays and maps. The tag of a field
+// of slices, arrays or maps of strings applies to each string. The struct must be passed
+// by pointer for its fields to be modified.
+func (v *Validator) Sanitize(s any) error {
+	return v.sanitizeValue(reflect.ValueOf(s), nil)
+}
+
+// sanitizeValue applies the sanitizers to the strings of the value and the
+// SanitizeTag of the fields of its structs
+func (v *Validator) sanitizeValue(value reflect.Value, fns []SanitizeFunc) error {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return v.sanitizeValue(value.Elem(), fns)
+	case reflect.String:
+		if len(fns) > 0 && value.CanSet() {
+			s := value.String()
+			for _, fn := range fns {
+				s = fn(s)
+			}
+			value.SetString(s)
+		}
+	case reflect.Struct:
+		typ := value.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldFns, err := v.sanitizers(field.Tag.Get(SanitizeTag))
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", typ.Name(), field.Name, err)
+			}
+			if err := v.sanitizeValue(value.Field(i), fieldFns); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := v.sanitizeValue(value.Index(i), fns); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			elem := reflect.New(value.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			if err := v.sanitizeValue(elem, fns); err != nil {
+				return err
+			}
+			value.SetMapIndex(iter.Key(), elem)
+		}
+	}
+	return nil
+}
+
+// sanitizers returns the sanitizers listed in a SanitizeTag value
+func (v *Validator) sanitizers(tag string) ([]SanitizeFunc, error) {
+	if tag == "" {
+		return nil, nil
+	}
+	var fns []SanitizeFunc
+	for _, name := range strings.Split(tag, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		fn, ok := v.sanitizerFuncs[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrSanitizerUnknown, name)
+		}
+		fns = append(fns, fn)
+	}
+	return fns, nil
+}
+
+// RegisterSanitizer registers a sanitizer usable in the SanitizeTag with DefaultValidator
+func RegisterSanitizer(name string, fn SanitizeFunc) {
+	DefaultValidator.RegisterSanitizer(name, fn)
+}
+
+// Sanitize applies the sanitizers of the SanitizeTag of the string fields of a struct using DefaultValidator
+func Sanitize(s any) error {
+	return DefaultValidator.Sanitize(s)
+}
@@ -0,0 +1,101 @@
+package utils_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+type SanitizeAddress struct {
+	City string `json:"city" mod:"collapse_spaces,title" validate:"required"`
+}
+
+type SanitizeCustomer struct {
+	Name      string             `json:"name" mod:"trim,collapse_spaces"`
+	Email     string             `json:"email" mod:"strip_zero_width,trim,lower" validate:"required,email"`
+	Code      *string            `json:"code" mod:"remove_spaces,upper"`
+	Tags      []string           `json:"tags" mod:"trim,lower"`
+	Labels    map[string]string  `json:"labels" mod:"trim"`
+	Address   SanitizeAddress    `json:"address"`
+	Addresses []*SanitizeAddress `json:"addresses"`
+	Raw       string             `json:"raw"`
+}
+
+func TestSanitize(t *testing.T) {
+	code := " ab c "
+	customer := SanitizeCustomer{
+		Name:      "  John \u200b  Doe ",
+		Email:     " John\u200b@Example.COM ",
+		Code:      &code,
+		Tags:      []string{" VIP ", "New"},
+		Labels:    map[string]string{"a": " x "},
+		Address:   SanitizeAddress{City: "  bangkok   noi "},
+		Addresses: []*SanitizeAddress{{City: "chiang  MAI"}, nil},
+		Raw:       "  raw  ",
+	}
+	assert.NoError(t, utils.Sanitize(&customer))
+	assert.Equal(t, "John Doe", customer.Name)
+	assert.Equal(t, "john@example.com", customer.Email)
+	assert.Equal(t, "ABC", *customer.Code)
+	assert.Equal(t, []string{"vip", "new"}, customer.Tags)
+	assert.Equal(t, map[string]string{"a": "x"}, customer.Labels)
+	assert.Equal(t, "Bangkok Noi", customer.Address.City)
+	assert.Equal(t, "Chiang Mai", customer.Addresses[0].City)
+	assert.Equal(t, "  raw  ", customer.Raw)
+}
+
+func TestSanitize_UnknownSanitizer(t *testing.T) {
+	var data struct {
+		Name string `mod:"trim,unknown"`
+	}
+	err := utils.Sanitize(&data)
+	assert.ErrorIs(t, err, utils.ErrSanitizerUnknown)
+}
+
+func TestValidator_RegisterSanitizer(t *testing.T) {
+	v := utils.NewValidator(utils.ValidatorConfig{})
+	v.RegisterSanitizer("digits", func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r < '0' || r > '9' {
+				return -1
+			}
+			return r
+		}, s)
+	})
+	data := struct {
+		Phone string `mod:"digits"`
+	}{Phone: "081-234-5678"}
+	assert.NoError(t, v.Sanitize(&data))
+	assert.Equal(t, "0812345678", data.Phone)
+	assert.ErrorIs(t, utils.Sanitize(&data), utils.ErrSanitizerUnknown)
+}
+
+func TestJsonUtil_ParseAndValidate_Sanitize(t *testing.T) {
+	var customers []SanitizeCustomer
+	err := utils.JSON.ParseAndValidate(`[{"email":" A@B.com ","address":{"city":" x "}}]`, &customers)
+	assert.NoError(t, err)
+	assert.Equal(t, "a@b.com", customers[0].Email)
+	assert.Equal(t, "X", customers[0].Address.City)
+
+	var customer SanitizeCustomer
+	err = utils.JSON.ParseAndValidate(`{"email":"a@b.com","address":{"city":" \u200b "}}`, &customer)
+	assert.EqualError(t, err, "Validation failed for 'city'")
+}
+
+func TestEchoBinderWithValidation_Bind_Sanitize(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":" Jane  Doe ","email":" JANE@EXAMPLE.COM","address":{"city":"bangkok"}}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	c := e.NewContext(req, httptest.NewRecorder())
+	var customer SanitizeCustomer
+	assert.NoError(t, utils.EchoBinder.Bind(&customer, c))
+	assert.Equal(t, "Jane Doe", customer.Name)
+	assert.Equal(t, "jane@example.com", customer.Email)
+	assert.Equal(t, "Bangkok", customer.Address.City)
+}
@@ -24,8 +24,9 @@ const (
 )
 
 var (
-	ErrSessionNotFound = errors.New("session not found") // Error for when session is not found.
-	ErrSessionInvalid  = errors.New("invalid session")   // Error for when session is invalid.
+	ErrSessionNotFound             = errors.New("session not found")              // Error for when session is not found.
+	ErrSessionInvalid              = errors.New("invalid session")                // Error for when session is invalid.
+	ErrSessionHandlerNotConfigured = errors.New("session handler not configured") // Error for when no session handler is configured.
 )
 
 // SessionRedisConfig is used to configure session data stored in Redis.
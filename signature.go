@@ -0,0 +1,304 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signature utility instance
+var Signature SignatureUtil
+
+// SignatureUtil provides methods for signing HTTP requests with HMAC-SHA256
+type SignatureUtil struct{}
+
+// Default values for SignatureVerifyConfig
+const (
+	DefaultSignatureTolerance       = 5 * time.Minute
+	DefaultSignatureNonceKeyPrefix  = "signature_nonce"
+	DefaultSignatureUntimedNonceTTL = 24 * time.Hour
+)
+
+// Default headers of DefaultSignatureScheme
+const (
+	DefaultSignatureHeader          = "X-Signature"
+	DefaultSignatureTimestampHeader = "X-Signature-Timestamp"
+	DefaultSignatureNonceHeader     = "X-Signature-Nonce"
+)
+
+var (
+	ErrSignatureMissing  = errors.New("missing request signature")                   // Error for requests without signature headers.
+	ErrSignatureInvalid  = errors.New("invalid request signature")                   // Error for signatures that do not match.
+	ErrSignatureExpired  = errors.New("request signature timestamp is out of range") // Error for timestamps outside the tolerance.
+	ErrSignatureReplayed = errors.New("request signature was already used")          // Error for replayed requests.
+)
+
+// SignaturePayload is the request data covered by a signature
+type SignaturePayload struct {
+	Method    string // HTTP method of the request
+	Path      string // Path and query of the request
+	Timestamp int64  // Unix time of the signature, zero for schemes without timestamps
+	Nonce     string // Unique value of the request, empty for schemes without nonces
+	Body      []byte // Body of the request
+}
+
+// SignatureHeader is the signature data read from the request headers
+type SignatureHeader struct {
+	Timestamp  int64    // Unix time of the signature, zero for schemes without timestamps
+	Nonce      string   // Unique value of the request, empty for schemes without nonces
+	Signatures [][]byte // Signatures of the request, any of which may match
+}
+
+// SignatureScheme defines how a signature is computed and carried in the request headers,
+// allowing to sign and verify the header formats of different webhook providers
+type SignatureScheme interface {
+	Sign(header http.Header, secret []byte, payload SignaturePayload)
+	Parse(header http.Header) (SignatureHeader, error)
+	Signature(secret []byte, payload SignaturePayload) []byte
+}
+
+// SignatureConfig is the configuration for signing requests
+type SignatureConfig struct {
+	Secret []byte          // The shared HMAC secret
+	Scheme SignatureScheme // The signature scheme, defaults to DefaultSignatureScheme
+}
+
+// SignatureVerifyConfig is the configuration for verifying signed requests
+type SignatureVerifyConfig struct {
+	Secret         []byte          // The shared HMAC secret
+	Scheme         SignatureScheme // The signature scheme, defaults to DefaultSignatureScheme
+	Tolerance      time.Duration   // The maximum difference between the signature timestamp and now, defaults to DefaultSignatureTolerance
+	Client         *RedisClient    // Optional Redis client storing used nonces to reject replayed requests
+	NonceKeyPrefix string          // Prefix for the Redis keys of used nonces, defaults to DefaultSignatureNonceKeyPrefix
+	NonceTTL       time.Duration   // How long used nonces are kept, defaults to twice the tolerance, or DefaultSignatureUntimedNonceTTL for schemes without timestamps
+}
+
+// SignRequest signs an outgoing request using the current time and a random nonce
+func (SignatureUtil) SignRequest(req *http.Request, config SignatureConfig) error {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return err
+	}
+	scheme := config.Scheme
+	if scheme == nil {
+		scheme = DefaultSignatureScheme{}
+	}
+	scheme.Sign(req.Header, config.Secret, SignaturePayload{
+		Method:    req.Method,
+		Path:      req.URL.RequestURI(),
+		Timestamp: time.Now().Unix(),
+		Nonce:     String.UUID(),
+		Body:      body,
+	})
+	return nil
+}
+
+// VerifyRequest verifies the signature of an incoming request. The signature timestamp must be
+// within the tolerance, and with a Redis client each nonce, or each signature for schemes
+// without nonces, is only accepted once within the nonce TTL. Schemes without timestamps
+// cannot reject requests replayed after the nonce TTL.
+func (SignatureUtil) VerifyRequest(req *http.Request, config SignatureVerifyConfig) error {
+	if config.Scheme == nil {
+		config.Scheme = DefaultSignatureScheme{}
+	}
+	if config.Tolerance <= 0 {
+		config.Tolerance = DefaultSignatureTolerance
+	}
+	if config.NonceKeyPrefix == "" {
+		config.NonceKeyPrefix = DefaultSignatureNonceKeyPrefix
+	}
+	header, err := config.Scheme.Parse(req.Header)
+	if err != nil {
+		return err
+	}
+	if header.Timestamp != 0 {
+		age := time.Since(time.Unix(header.Timestamp, 0))
+		if age > config.Tolerance || age < -config.Tolerance {
+			return ErrSignatureExpired
+		}
+	}
+	body, err := readRequestBody(req)
+	if err != nil {
+		return err
+	}
+	expected := config.Scheme.Signature(config.Secret, SignaturePayload{
+		Method:    req.Method,
+		Path:      req.URL.RequestURI(),
+		Timestamp: header.Timestamp,
+		Nonce:     header.Nonce,
+		Body:      body,
+	})
+	valid := false
+	for _, signature := range header.Signatures {
+		if hmac.Equal(expected, signature) {
+			valid = true
+		}
+	}
+	if !valid {
+		return ErrSignatureInvalid
+	}
+	if config.Client == nil {
+		return nil
+	}
+	nonce := header.Nonce
+	if nonce == "" {
+		nonce = hex.EncodeToString(expected)
+	}
+	ttl := config.NonceTTL
+	if ttl <= 0 {
+		ttl = 2 * config.Tolerance
+		if header.Timestamp == 0 {
+			ttl = DefaultSignatureUntimedNonceTTL
+		}
+	}
+	ok, err := config.Client.SetNXStruct(config.NonceKeyPrefix+":"+String.SHA256(nonce), header.Timestamp, ttl)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSignatureReplayed
+	}
+	return nil
+}
+
+// readRequestBody reads the body of a request and restores it for further reading
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return []byte{}, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of the data using the secret
+func hmacSHA256(secret []byte, data ...[]byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+// DefaultSignatureScheme signs the method, path, timestamp, nonce and body hash,
+// separated by newlines, using the X-Signature headers with a hex encoded signature
+type DefaultSignatureScheme struct{}
+
+// Sign sets the signature headers
+func (s DefaultSignatureScheme) Sign(header http.Header, secret []byte, payload SignaturePayload) {
+	header.Set(DefaultSignatureTimestampHeader, strconv.FormatInt(payload.Timestamp, 10))
+	header.Set(DefaultSignatureNonceHeader, payload.Nonce)
+	header.Set(DefaultSignatureHeader, hex.EncodeToString(s.Signature(secret, payload)))
+}
+
+// Parse reads the signature headers
+func (DefaultSignatureScheme) Parse(header http.Header) (SignatureHeader, error) {
+	signature, err := hex.DecodeString(header.Get(DefaultSignatureHeader))
+	if err != nil || len(signature) == 0 {
+		return SignatureHeader{}, ErrSignatureMissing
+	}
+	timestamp, err := strconv.ParseInt(header.Get(DefaultSignatureTimestampHeader), 10, 64)
+	if err != nil || timestamp <= 0 {
+		return SignatureHeader{}, ErrSignatureMissing
+	}
+	nonce := header.Get(DefaultSignatureNonceHeader)
+	if nonce == "" {
+		return SignatureHeader{}, ErrSignatureMissing
+	}
+	return SignatureHeader{Timestamp: timestamp, Nonce: nonce, Signatures: [][]byte{signature}}, nil
+}
+
+// Signature computes the signature of the payload
+func (DefaultSignatureScheme) Signature(secret []byte, payload SignaturePayload) []byte {
+	bodyHash := sha256.Sum256(payload.Body)
+	stringToSign := strings.Join([]string{
+		payload.Method,
+		payload.Path,
+		strconv.FormatInt(payload.Timestamp, 10),
+		payload.Nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+	return hmacSHA256(secret, []byte(stringToSign))
+}
+
+// StripeSignatureScheme signs the timestamp and body using the Stripe-Signature
+// header in the format "t=<timestamp>,v1=<hex signature>"
+type StripeSignatureScheme struct{}
+
+// stripeSignatureHeader is the header of StripeSignatureScheme
+const stripeSignatureHeader = "Stripe-Signature"
+
+// Sign sets the signature header
+func (s StripeSignatureScheme) Sign(header http.Header, secret []byte, payload SignaturePayload) {
+	signature := hex.EncodeToString(s.Signature(secret, payload))
+	header.Set(stripeSignatureHeader, "t="+strconv.FormatInt(payload.Timestamp, 10)+",v1="+signature)
+}
+
+// Parse reads the signature header, accepting multiple v1 signatures
+func (StripeSignatureScheme) Parse(header http.Header) (SignatureHeader, error) {
+	result := SignatureHeader{}
+	for _, item := range strings.Split(header.Get(stripeSignatureHeader), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(item), "=")
+		switch key {
+		case "t":
+			result.Timestamp, _ = strconv.ParseInt(value, 10, 64)
+		case "v1":
+			if signature, err := hex.DecodeString(value); err == nil {
+				result.Signatures = append(result.Signatures, signature)
+			}
+		}
+	}
+	if result.Timestamp <= 0 || len(result.Signatures) == 0 {
+		return SignatureHeader{}, ErrSignatureMissing
+	}
+	return result, nil
+}
+
+// Signature computes the signature of the payload
+func (StripeSignatureScheme) Signature(secret []byte, payload SignaturePayload) []byte {
+	return hmacSHA256(secret, []byte(strconv.FormatInt(payload.Timestamp, 10)), []byte("."), payload.Body)
+}
+
+// GitHubSignatureScheme signs the body only using the X-Hub-Signature-256 header
+// in the format "sha256=<hex signature>". It has no timestamp, so replay protection
+// relies on the nonce store alone: a signature is rejected when seen again within the
+// nonce TTL, DefaultSignatureUntimedNonceTTL by default, but a request replayed after the
+// TTL verifies again. The unsigned X-GitHub-Delivery header is not used as the nonce,
+// as it could be changed to replay a request.
+type GitHubSignatureScheme struct{}
+
+// GitHub signature header and prefix of GitHubSignatureScheme
+const (
+	gitHubSignatureHeader = "X-Hub-Signature-256"
+	gitHubSignaturePrefix = "sha256="
+)
+
+// Sign sets the signature header
+func (s GitHubSignatureScheme) Sign(header http.Header, secret []byte, payload SignaturePayload) {
+	header.Set(gitHubSignatureHeader, gitHubSignaturePrefix+hex.EncodeToString(s.Signature(secret, payload)))
+}
+
+// Parse reads the signature header
+func (GitHubSignatureScheme) Parse(header http.Header) (SignatureHeader, error) {
+	value, ok := strings.CutPrefix(header.Get(gitHubSignatureHeader), gitHubSignaturePrefix)
+	signature, err := hex.DecodeString(value)
+	if !ok || err != nil || len(signature) == 0 {
+		return SignatureHeader{}, ErrSignatureMissing
+	}
+	return SignatureHeader{Signatures: [][]byte{signature}}, nil
+}
+
+// Signature computes the signature of the payload
+func (GitHubSignatureScheme) Signature(secret []byte, payload SignaturePayload) []byte {
+	return hmacSHA256(secret, payload.Body)
+}
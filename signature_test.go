@@ -0,0 +1,125 @@
+package utils_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+var testSignatureSecret = []byte("my-webhook-secret")
+
+func newTestSignedRequest(t *testing.T, scheme utils.SignatureScheme, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/webhook?source=test", strings.NewReader(body))
+	err := utils.Signature.SignRequest(req, utils.SignatureConfig{Secret: testSignatureSecret, Scheme: scheme})
+	assert.NoError(t, err)
+	return req
+}
+
+func TestSignatureUtil_SignVerify(t *testing.T) {
+	schemes := map[string]utils.SignatureScheme{
+		"default": nil,
+		"stripe":  utils.StripeSignatureScheme{},
+		"github":  utils.GitHubSignatureScheme{},
+	}
+	for name, scheme := range schemes {
+		t.Run(name, func(t *testing.T) {
+			config := utils.SignatureVerifyConfig{Secret: testSignatureSecret, Scheme: scheme}
+			req := newTestSignedRequest(t, scheme, `{"id":1}`)
+			body, _ := io.ReadAll(req.Body)
+			assert.Equal(t, `{"id":1}`, string(body))
+
+			req = newTestSignedRequest(t, scheme, `{"id":1}`)
+			assert.NoError(t, utils.Signature.VerifyRequest(req, config))
+			body, _ = io.ReadAll(req.Body)
+			assert.Equal(t, `{"id":1}`, string(body), "body is restored")
+
+			tampered := newTestSignedRequest(t, scheme, `{"id":1}`)
+			tampered.Body = io.NopCloser(strings.NewReader(`{"id":2}`))
+			assert.ErrorIs(t, utils.Signature.VerifyRequest(tampered, config), utils.ErrSignatureInvalid)
+
+			wrongSecret := newTestSignedRequest(t, scheme, `{"id":1}`)
+			config.Secret = []byte("other-secret")
+			assert.ErrorIs(t, utils.Signature.VerifyRequest(wrongSecret, config), utils.ErrSignatureInvalid)
+
+			unsigned := httptest.NewRequest(http.MethodPost, "/webhook", nil)
+			assert.ErrorIs(t, utils.Signature.VerifyRequest(unsigned, config), utils.ErrSignatureMissing)
+		})
+	}
+}
+
+func TestSignatureUtil_VerifyRequest_Expired(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader("{}"))
+	scheme := utils.StripeSignatureScheme{}
+	scheme.Sign(req.Header, testSignatureSecret, utils.SignaturePayload{
+		Timestamp: time.Now().Add(-10 * time.Minute).Unix(),
+		Body:      []byte("{}"),
+	})
+	err := utils.Signature.VerifyRequest(req, utils.SignatureVerifyConfig{Secret: testSignatureSecret, Scheme: scheme})
+	assert.ErrorIs(t, err, utils.ErrSignatureExpired)
+}
+
+func TestSignatureUtil_VerifyRequest_InvalidTimestamp(t *testing.T) {
+	schemes := map[string]utils.SignatureScheme{
+		"default": utils.DefaultSignatureScheme{},
+		"stripe":  utils.StripeSignatureScheme{},
+	}
+	for name, scheme := range schemes {
+		for _, timestamp := range []int64{0, -1} {
+			t.Run(name+"/"+strconv.FormatInt(timestamp, 10), func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader("{}"))
+				scheme.Sign(req.Header, testSignatureSecret, utils.SignaturePayload{
+					Method:    http.MethodPost,
+					Path:      "/webhook",
+					Timestamp: timestamp,
+					Nonce:     "nonce",
+					Body:      []byte("{}"),
+				})
+				err := utils.Signature.VerifyRequest(req, utils.SignatureVerifyConfig{Secret: testSignatureSecret, Scheme: scheme})
+				assert.ErrorIs(t, err, utils.ErrSignatureMissing)
+			})
+		}
+	}
+}
+
+func TestSignatureUtil_VerifyRequest_UntimedReplay(t *testing.T) {
+	s, client := newTestRedisClient(t)
+	scheme := utils.GitHubSignatureScheme{}
+	config := utils.SignatureVerifyConfig{Secret: testSignatureSecret, Scheme: scheme, Client: client}
+	req := newTestSignedRequest(t, scheme, `{"id":1}`)
+	replay := newTestSignedRequest(t, scheme, `{"id":1}`)
+	assert.NoError(t, utils.Signature.VerifyRequest(req, config))
+	assert.ErrorIs(t, utils.Signature.VerifyRequest(replay, config), utils.ErrSignatureReplayed)
+	keys := s.Keys()
+	assert.Len(t, keys, 1)
+	assert.Equal(t, utils.DefaultSignatureUntimedNonceTTL, s.TTL(keys[0]))
+
+	config.NonceTTL = time.Hour
+	req = newTestSignedRequest(t, scheme, `{"id":2}`)
+	assert.NoError(t, utils.Signature.VerifyRequest(req, config))
+	for _, key := range s.Keys() {
+		if key != keys[0] {
+			assert.Equal(t, time.Hour, s.TTL(key))
+		}
+	}
+}
+
+func TestStripeSignatureScheme_Parse(t *testing.T) {
+	header := http.Header{}
+	header.Set("Stripe-Signature", "t=1700000000,v1=00ff,v0=abcd,v1=0a0b")
+	result, err := utils.StripeSignatureScheme{}.Parse(header)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1700000000), result.Timestamp)
+	assert.Equal(t, [][]byte{{0x00, 0xff}, {0x0a, 0x0b}}, result.Signatures)
+
+	header.Set("Stripe-Signature", "t="+strconv.Itoa(0))
+	_, err = utils.StripeSignatureScheme{}.Parse(header)
+	assert.ErrorIs(t, err, utils.ErrSignatureMissing)
+}
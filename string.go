@@ -214,13 +214,18 @@ func (StringUtil) ValidateEMVCoQRString(qrString string) error {
 	return nil
 }
 
-// Encrypt encrypts the given plaintext using AES encryption with the provided key.
-func (StringUtil) AESEncrypt(key, plaintext string) (string, error) {
-	block, err := aes.NewCipher([]byte(key))
+// newAESGCM returns an AES-GCM cipher using the provided key.
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	gcm, err := cipher.NewGCM(block)
+	return cipher.NewGCM(block)
+}
+
+// Encrypt encrypts the given plaintext using AES encryption with the provided key.
+func (StringUtil) AESEncrypt(key, plaintext string) (string, error) {
+	gcm, err := newAESGCM([]byte(key))
 	if err != nil {
 		return "", err
 	}
@@ -234,11 +239,7 @@ func (StringUtil) AESEncrypt(key, plaintext string) (string, error) {
 
 // Decrypt decrypts the given cipherText using AES decryption with the provided key.
 func (StringUtil) AESDecrypt(key, cipherText string) (string, error) {
-	block, err := aes.NewCipher([]byte(key))
-	if err != nil {
-		return "", err
-	}
-	gcm, err := cipher.NewGCM(block)
+	gcm, err := newAESGCM([]byte(key))
 	if err != nil {
 		return "", err
 	}
@@ -1,10 +1,17 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
+	"maps"
 	"reflect"
 	"regexp"
 	"strings"
+	"sync"
 
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/th"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -13,16 +20,140 @@ type Result struct {
 	List any `validate:"dive"`
 }
 
-// Validate a new instance of the validator library.
-var Validate = validator.New()
+// ValidatorConfig holds the configuration of a Validator
+type ValidatorConfig struct {
+	TagNameFunc     validator.TagNameFunc // Function returning the field names of validation errors, defaults to GetJSONTagName
+	SkipBundledTags bool                  // Whether to skip registering number_string and the Thai and number format tags
+}
+
+// Validator is an isolated validator with its own tag name function, custom validation tags,
+// messages, async validations and sanitizers, allowing modules to register tags without colliding
+type Validator struct {
+	validate   *validator.Validate
+	translator *ut.UniversalTranslator
+	asyncMu    sync.RWMutex
+	asyncFuncs map[reflect.Type][]AsyncValidationFunc
+
+	sanitizerFuncs map[string]SanitizeFunc
+}
+
+// DefaultValidator is the validator used by ValidateStruct and the package level registration functions
+var DefaultValidator = NewValidator(ValidatorConfig{})
+
+// Validate the instance of the validator library of DefaultValidator.
+var Validate = DefaultValidator.Instance()
 
 // RegExpNumberString a regular expression to match number strings
 // with optional thousands separators and decimal portions.
 var RegExpNumberString = regexp.MustCompile(`^-?([1-9]{1}\d{0,2}(\,\d{3})*(\.\d+)?|[1-9]{1}\d*(\.\d+)?|0(\.\d+)?|(\.\d+)?)$`)
 
-func init() {
-	Validate.RegisterTagNameFunc(GetJSONTagName)
-	_ = Validate.RegisterValidation("number_string", ValidateNumberString)
+// NewValidator creates a Validator with the English and Thai messages of the common
+// validation tags and, unless skipped, the tags bundled with this package
+func NewValidator(config ValidatorConfig) *Validator {
+	if config.TagNameFunc == nil {
+		config.TagNameFunc = GetJSONTagName
+	}
+	v := &Validator{
+		validate:   validator.New(),
+		translator: ut.New(en.New(), en.New(), th.New()),
+		asyncFuncs: map[reflect.Type][]AsyncValidationFunc{},
+
+		sanitizerFuncs: maps.Clone(defaultSanitizers),
+	}
+	v.validate.RegisterTagNameFunc(config.TagNameFunc)
+	v.registerDefaultTranslations()
+	if !config.SkipBundledTags {
+		v.registerBundledTags()
+	}
+	return v
+}
+
+// Instance returns the underlying instance of the validator library
+func (v *Validator) Instance() *validator.Validate {
+	return v.validate
+}
+
+// Translator returns the universal translator of the validation messages.
+// Other languages can be added with AddTranslator before registering their messages.
+func (v *Validator) Translator() *ut.UniversalTranslator {
+	return v.translator
+}
+
+// Struct validates the fields of a struct like ValidateStruct using the validator
+func (v *Validator) Struct(s any) error {
+	if err := v.validate.Struct(s); err != nil {
+		validationErr := v.ParseError(err)
+		applyValidationFields(s, &validationErr)
+		return validationErr
+	}
+	return nil
+}
+
+// ParseError converts an error into a ValidationError like ParseValidationError,
+// using the English messages registered with the validator
+func (v *Validator) ParseError(err error) ValidationError {
+	var validationErr ValidationError
+	var validationErrs validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErr):
+		return validationErr
+	case errors.As(err, &validationErrs):
+		err := validationErrs
+		errDetailList := make([]ValidationErrorDetail, len(err))
+		fieldList := make([]string, len(err))
+		for i, e := range err {
+			fieldList[i] = fmt.Sprintf("'%s'", e.Field())
+			errDetailList[i] = ValidationErrorDetail{
+				Field:   e.Field(),
+				Path:    validationPath(e.Namespace(), e.StructNamespace()),
+				Tag:     e.Tag(),
+				Param:   e.Param(),
+				Message: v.message(e, nil),
+			}
+		}
+		return ValidationError{
+			ErrorMessage: fmt.Sprintf("Validation failed for %s", strings.Join(fieldList, ", ")),
+			Details:      errDetailList,
+			Errors:       err,
+			validator:    v.errorValidator(),
+		}
+	default:
+		return ValidationError{
+			ErrorMessage: err.Error(),
+		}
+	}
+}
+
+// errorValidator returns the validator kept in its validation errors,
+// nil for DefaultValidator, which is used for errors without a validator
+func (v *Validator) errorValidator() *Validator {
+	if v == DefaultValidator {
+		return nil
+	}
+	return v
+}
+
+// validatorOrDefault returns the validator, or DefaultValidator when it's nil
+func validatorOrDefault(v *Validator) *Validator {
+	if v == nil {
+		return DefaultValidator
+	}
+	return v
+}
+
+// registerBundledTags registers number_string and the Thai and number format tags
+func (v *Validator) registerBundledTags() {
+	_ = v.RegisterTag("number_string", ValidateNumberString, map[string]string{
+		"en": "{0} must be a valid number",
+		"th": "{0} ต้องเป็นตัวเลขที่ถูกต้อง",
+	})
+	for _, t := range bundledValidationTags {
+		_ = v.RegisterTag(t.tag, t.fn, t.messages)
+	}
+	_ = v.RegisterTranslation("timezone", map[string]string{
+		"en": "{0} must be a valid time zone",
+		"th": "{0} ต้องเป็นเขตเวลาที่ถูกต้อง",
+	})
 }
 
 // ValidateNumberString validates a given number string by checking whether
@@ -40,12 +171,11 @@ func GetJSONTagName(field reflect.StructField) string {
 	return tagName
 }
 
-// ValidateStruct validates the fields of a struct using the validator library.
+// ValidateStruct validates the fields of a struct using DefaultValidator.
 // if validation fails, it calls the ParseValidationError() function
-// to convert ValidationErrors into a ValidationError error type.
+// to convert ValidationErrors into a ValidationError error type,
+// adding the values of the failed fields, with sensitive fields redacted,
+// and using the ValidationMessageTag of the failed fields as their messages.
 func ValidateStruct(s any) error {
-	if err := Validate.Struct(s); err != nil {
-		return ParseValidationError(err)
-	}
-	return nil
+	return DefaultValidator.Struct(s)
 }
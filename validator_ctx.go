@@ -0,0 +1,180 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ValidationDependencies holds the dependencies injected into context-aware validators
+type ValidationDependencies struct {
+	Redis  *RedisClient   // Redis client, e.g. for uniqueness checks
+	Values map[string]any // Other dependencies and request-scoped data by name
+}
+
+// AsyncValidationFunc validates a struct using the context and its injected dependencies,
+// returning the details of the failed fields. The struct is passed by value, also when it's
+// validated through a pointer. Details without a message use the message
+// registered for their tag. Errors are returned by ValidateStructCtx as is, e.g. when Redis is down.
+type AsyncValidationFunc func(ctx context.Context, deps ValidationDependencies, s any) ([]ValidationErrorDetail, error)
+
+// ErrAsyncValidationPanic is returned by ValidateStructCtx when an async validation function panics
+var ErrAsyncValidationPanic = errors.New("async validation panicked")
+
+// validationDependenciesKey is the context key of the injected ValidationDependencies
+type validationDependenciesKey struct{}
+
+// WithValidationDependencies returns a copy of the context carrying the validation dependencies
+func WithValidationDependencies(ctx context.Context, deps ValidationDependencies) context.Context {
+	return context.WithValue(ctx, validationDependenciesKey{}, deps)
+}
+
+// GetValidationDependencies returns the validation dependencies injected into the context
+func GetValidationDependencies(ctx context.Context) ValidationDependencies {
+	deps, _ := ctx.Value(validationDependenciesKey{}).(ValidationDependencies)
+	return deps
+}
+
+// RegisterStructValidationCtx registers a context-aware struct-level validation function for the
+// types, run by StructCtx. Errors reported with ReportError use the messages of their tags.
+func (v *Validator) RegisterStructValidationCtx(fn validator.StructLevelFuncCtx, types ...any) {
+	v.validate.RegisterStructValidationCtx(fn, types...)
+}
+
+// RegisterTagCtx registers a context-aware custom validation tag together with its messages by language
+func (v *Validator) RegisterTagCtx(tag string, fn validator.FuncCtx, messages map[string]string) error {
+	if err := v.validate.RegisterValidationCtx(tag, fn); err != nil {
+		return err
+	}
+	return v.RegisterTranslation(tag, messages)
+}
+
+// RegisterAsyncValidation registers an async validation function for the types, run concurrently
+// with the other async validations of the type by StructCtx after the field validation.
+// Like RegisterStructValidationCtx, structs and pointers to them are matched alike.
+// Only the validated struct itself is matched, not its nested structs.
+func (v *Validator) RegisterAsyncValidation(fn AsyncValidationFunc, types ...any) {
+	v.asyncMu.Lock()
+	defer v.asyncMu.Unlock()
+	for _, t := range types {
+		typ := asyncValidationType(t)
+		v.asyncFuncs[typ] = append(v.asyncFuncs[typ], fn)
+	}
+}
+
+// StructCtx validates a struct like Struct using the context, which carries the dependencies
+// injected with WithValidationDependencies, and runs the async validations of the struct type.
+// The failed fields of all validations are merged into one ValidationError.
+func (v *Validator) StructCtx(ctx context.Context, s any) error {
+	validationErr := ValidationError{validator: v.errorValidator()}
+	if err := v.validate.StructCtx(ctx, s); err != nil {
+		var validationErrs validator.ValidationErrors
+		if !errors.As(err, &validationErrs) {
+			return v.ParseError(err)
+		}
+		validationErr = v.ParseError(err)
+		applyValidationFields(s, &validationErr)
+	}
+	details, err := v.runAsyncValidations(ctx, s)
+	if err != nil {
+		return err
+	}
+	if len(details) == 0 {
+		if len(validationErr.Details) == 0 {
+			return nil
+		}
+		return validationErr
+	}
+	validationErr.Details = append(validationErr.Details, details...)
+	fieldList := make([]string, len(validationErr.Details))
+	for i, detail := range validationErr.Details {
+		fieldList[i] = fmt.Sprintf("'%s'", detail.Field)
+	}
+	validationErr.ErrorMessage = fmt.Sprintf("Validation failed for %s", strings.Join(fieldList, ", "))
+	return validationErr
+}
+
+// runAsyncValidations runs the async validations of the struct type concurrently
+// and returns their details in registration order. Panics are returned as errors.
+func (v *Validator) runAsyncValidations(ctx context.Context, s any) ([]ValidationErrorDetail, error) {
+	v.asyncMu.RLock()
+	funcs := v.asyncFuncs[asyncValidationType(s)]
+	v.asyncMu.RUnlock()
+	if len(funcs) == 0 {
+		return nil, nil
+	}
+	deps := GetValidationDependencies(ctx)
+	value := reflect.ValueOf(s)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	s = value.Interface()
+	results := make([][]ValidationErrorDetail, len(funcs))
+	errs := make([]error, len(funcs))
+	var wg sync.WaitGroup
+	for i, fn := range funcs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					errs[i] = fmt.Errorf("%w: %v", ErrAsyncValidationPanic, r)
+				}
+			}()
+			results[i], errs[i] = fn(ctx, deps, s)
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	details := []ValidationErrorDetail{}
+	for _, result := range results {
+		for _, detail := range result {
+			if detail.Path == "" {
+				detail.Path = detail.Field
+			}
+			if detail.Message == "" {
+				detail.Message = v.tagMessage(nil, detail)
+			}
+			details = append(details, detail)
+		}
+	}
+	return details, nil
+}
+
+// asyncValidationType returns the type of the async validations of a value, dereferencing pointers
+func asyncValidationType(s any) reflect.Type {
+	typ := reflect.TypeOf(s)
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+// RegisterStructValidationCtx registers a context-aware struct-level validation function for the types with DefaultValidator
+func RegisterStructValidationCtx(fn validator.StructLevelFuncCtx, types ...any) {
+	DefaultValidator.RegisterStructValidationCtx(fn, types...)
+}
+
+// RegisterValidationTagCtx registers a context-aware custom validation tag together with its messages by language with DefaultValidator
+func RegisterValidationTagCtx(tag string, fn validator.FuncCtx, messages map[string]string) error {
+	return DefaultValidator.RegisterTagCtx(tag, fn, messages)
+}
+
+// RegisterAsyncValidation registers an async validation function for the types with DefaultValidator
+func RegisterAsyncValidation(fn AsyncValidationFunc, types ...any) {
+	DefaultValidator.RegisterAsyncValidation(fn, types...)
+}
+
+// ValidateStructCtx validates a struct like ValidateStruct using the context, which carries the
+// dependencies injected with WithValidationDependencies, and runs the async validations of the
+// struct type with DefaultValidator. The failed fields of all validations are merged into one ValidationError.
+func ValidateStructCtx(ctx context.Context, s any) error {
+	return DefaultValidator.StructCtx(ctx, s)
+}
@@ -0,0 +1,114 @@
+package utils_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+type SignUp struct {
+	Username string `json:"username" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+	Confirm  string `json:"confirm"`
+	Referrer string `json:"referrer" validate:"omitempty,tenant_referrer"`
+}
+
+func init() {
+	utils.RegisterStructValidationCtx(func(ctx context.Context, sl validator.StructLevel) {
+		s := sl.Current().Interface().(SignUp)
+		if s.Password != s.Confirm {
+			sl.ReportError(s.Confirm, "confirm", "Confirm", "eqfield", "password")
+		}
+	}, SignUp{})
+	_ = utils.RegisterValidationTagCtx("tenant_referrer", func(ctx context.Context, fl validator.FieldLevel) bool {
+		tenant, _ := utils.GetValidationDependencies(ctx).Values["tenant"].(string)
+		return len(fl.Field().String()) > len(tenant) && fl.Field().String()[:len(tenant)] == tenant
+	}, map[string]string{"en": "{0} must be a referrer of the tenant"})
+	_ = utils.RegisterValidationTranslation("unique", map[string]string{
+		"en": "{0} is already taken",
+		"th": "{0} ถูกใช้งานแล้ว",
+	})
+	utils.RegisterAsyncValidation(func(ctx context.Context, deps utils.ValidationDependencies, s any) ([]utils.ValidationErrorDetail, error) {
+		signUp := s.(SignUp)
+		exists, err := deps.Redis.Exists(ctx, "username:"+signUp.Username).Result()
+		if err != nil || exists == 0 {
+			return nil, err
+		}
+		return []utils.ValidationErrorDetail{{Field: "username", Tag: "unique", Value: signUp.Username}}, nil
+	}, SignUp{})
+	utils.RegisterAsyncValidation(func(ctx context.Context, deps utils.ValidationDependencies, s any) ([]utils.ValidationErrorDetail, error) {
+		if s.(SignUp).Email == "blocked@example.com" {
+			return []utils.ValidationErrorDetail{{Field: "email", Tag: "blocked", Message: "email is blocked"}}, nil
+		}
+		return nil, nil
+	}, SignUp{})
+}
+
+func TestValidateStructCtx(t *testing.T) {
+	s, client := newTestRedisClient(t)
+	assert.NoError(t, client.Set(context.Background(), "username:taken", 1, time.Minute).Err())
+	ctx := utils.WithValidationDependencies(context.Background(), utils.ValidationDependencies{
+		Redis:  client,
+		Values: map[string]any{"tenant": "acme-"},
+	})
+
+	valid := SignUp{Username: "new", Email: "new@example.com", Password: "secret", Confirm: "secret", Referrer: "acme-1"}
+	assert.NoError(t, utils.ValidateStructCtx(ctx, valid))
+
+	invalid := SignUp{Username: "taken", Email: "blocked@example.com", Password: "secret", Confirm: "other", Referrer: "other-1"}
+	err := utils.ValidateStructCtx(ctx, invalid)
+	validationErr := utils.ParseValidationError(err)
+	assert.Equal(t, "Validation failed for 'referrer', 'confirm', 'username', 'email'", validationErr.ErrorMessage)
+	assert.Equal(t, map[string]string{
+		"referrer": "referrer must be a referrer of the tenant",
+		"confirm":  "confirm must be equal to password",
+		"username": "username is already taken",
+		"email":    "email is blocked",
+	}, messagesOf(validationErr.Details))
+	assert.Equal(t, "taken", validationErr.Details[2].Value)
+	assert.Equal(t, "username", validationErr.Details[2].Path)
+
+	translated := utils.TranslateValidationError(validationErr, "th")
+	assert.Equal(t, "username ถูกใช้งานแล้ว", messagesOf(translated.Details)["username"])
+	assert.Equal(t, "email is blocked", messagesOf(translated.Details)["email"])
+
+	err = utils.ValidateStructCtx(ctx, SignUp{Username: "taken", Email: "taken@example.com", Password: "p", Confirm: "p"})
+	assert.Equal(t, "Validation failed for 'username'", err.Error())
+	err = utils.ValidateStructCtx(ctx, &SignUp{Username: "taken", Email: "taken@example.com", Password: "p", Confirm: "p"})
+	assert.Equal(t, "Validation failed for 'username'", err.Error())
+
+	s.Close()
+	err = utils.ValidateStructCtx(ctx, valid)
+	assert.Error(t, err)
+	assert.False(t, utils.IsValidationError(err))
+
+	assert.EqualError(t, utils.ValidateStructCtx(ctx, nil), "validator: (nil)")
+	assert.Equal(t, utils.ValidationDependencies{}, utils.GetValidationDependencies(context.Background()))
+}
+
+func TestValidator_RegisterAsyncValidation(t *testing.T) {
+	type Profile struct {
+		Name string `json:"name"`
+	}
+	v := utils.NewValidator(utils.ValidatorConfig{})
+	v.RegisterAsyncValidation(func(ctx context.Context, deps utils.ValidationDependencies, s any) ([]utils.ValidationErrorDetail, error) {
+		if s.(Profile).Name == "panic" {
+			panic("boom")
+		}
+		return []utils.ValidationErrorDetail{{Field: "name", Tag: "checked", Message: "name was checked"}}, nil
+	}, &Profile{})
+
+	for _, profile := range []any{Profile{}, &Profile{}} {
+		err := v.StructCtx(context.Background(), profile)
+		assert.EqualError(t, err, "Validation failed for 'name'")
+	}
+	err := v.StructCtx(context.Background(), &Profile{Name: "panic"})
+	assert.ErrorIs(t, err, utils.ErrAsyncValidationPanic)
+	assert.EqualError(t, err, "async validation panicked: boom")
+}
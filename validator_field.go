@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ValidationSensitiveTag is the struct tag marking fields whose values are redacted
+// from validation error details, e.g. `sensitive:"true"`
+const ValidationSensitiveTag = "sensitive"
+
+// ValidationRedactedValue is the value of validation error details of sensitive fields
+const ValidationRedactedValue = "[REDACTED]"
+
+// resultListNamespace is the namespace of the list wrapped by Result
+const resultListNamespace = "Result.List"
+
+// validationPath returns the path of the field of a namespace using the JSON tag names,
+// without the root struct name, e.g. "items[2].price" for "Order.items[2].price".
+// Lists wrapped by Result start with the index, e.g. "[2].price".
+func validationPath(namespace, structNamespace string) string {
+	if strings.HasPrefix(structNamespace, resultListNamespace+"[") {
+		_, path, _ := strings.Cut(namespace, "[")
+		return "[" + path
+	}
+	_, path, ok := strings.Cut(namespace, ".")
+	if !ok {
+		return namespace
+	}
+	return path
+}
+
+// applyValidationFields sets the values of the validation error details from the struct,
+// redacting sensitive fields, and replaces the messages with the ValidationMessageTag of the fields
+func applyValidationFields(s any, err *ValidationError) {
+	for i, fe := range err.Errors {
+		if i >= len(err.Details) {
+			break
+		}
+		field, ok := validationStructField(s, fe.StructNamespace())
+		if !ok {
+			continue
+		}
+		err.Details[i].Value = fe.Value()
+		if sensitive, _ := strconv.ParseBool(field.Tag.Get(ValidationSensitiveTag)); sensitive {
+			err.Details[i].Value = ValidationRedactedValue
+		}
+		if message := field.Tag.Get(ValidationMessageTag); message != "" {
+			err.Details[i].Message = strings.NewReplacer("{0}", fe.Field(), "{1}", fe.Param()).Replace(message)
+		}
+	}
+}
+
+// validationStructField returns the struct field at the struct namespace,
+// e.g. "User.Addresses[0].City", walking the value to resolve interfaces and collections
+func validationStructField(s any, structNamespace string) (reflect.StructField, bool) {
+	segments := strings.Split(structNamespace, ".")
+	v := reflect.ValueOf(s)
+	var field reflect.StructField
+	for _, segment := range segments[1:] {
+		name, indexes, _ := strings.Cut(segment, "[")
+		v = indirectValue(v)
+		if v.Kind() != reflect.Struct {
+			return field, false
+		}
+		f, ok := v.Type().FieldByName(name)
+		if !ok {
+			return field, false
+		}
+		field = f
+		fv, err := v.FieldByIndexErr(f.Index)
+		if err != nil {
+			return field, false
+		}
+		v = fv
+		if indexes != "" {
+			for _, index := range strings.Split(strings.TrimSuffix(indexes, "]"), "][") {
+				v = elementValue(indirectValue(v), index)
+			}
+		}
+	}
+	return field, len(segments) > 1
+}
+
+// indirectValue returns the value pointed to by pointers and held by interfaces
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+// elementValue returns the element of a slice, array or map at the index of a namespace segment
+func elementValue(v reflect.Value, index string) reflect.Value {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if i, err := strconv.Atoi(index); err == nil && i >= 0 && i < v.Len() {
+			return v.Index(i)
+		}
+	case reflect.Map:
+		key := reflect.ValueOf(index)
+		if key.Type().ConvertibleTo(v.Type().Key()) {
+			return v.MapIndex(key.Convert(v.Type().Key()))
+		}
+	}
+	return reflect.Value{}
+}
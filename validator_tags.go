@@ -0,0 +1,232 @@
+package utils
+
+import (
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Regular expressions of the Thai validation tags, matched after removing dashes and spaces
+var (
+	RegExpThaiMobile     = regexp.MustCompile(`^(0|\+?66)[689]\d{8}$`) // Thai mobile numbers, e.g. 0812345678 or +66812345678
+	RegExpThaiPostalCode = regexp.MustCompile(`^[1-9]\d{4}$`)          // Thai postal codes, e.g. 10110
+	regExpDigits         = regexp.MustCompile(`^\d+$`)
+)
+
+// ThaiBankAccountLengths are the account number lengths by bank code used by the
+// thai_bank_account tag, e.g. `validate:"thai_bank_account=gsb"`
+var ThaiBankAccountLengths = map[string]int{
+	"bbl":   10,
+	"kbank": 10,
+	"ktb":   10,
+	"scb":   10,
+	"bay":   10,
+	"ttb":   10,
+	"kkp":   10,
+	"cimb":  10,
+	"uob":   10,
+	"tisco": 10,
+	"lhb":   10,
+	"icbc":  10,
+	"ibank": 10,
+	"gsb":   12,
+	"baac":  12,
+	"ghb":   12,
+}
+
+// defaultMoneyDecimalPlaces is the maximum number of decimal places of the money_amount tag
+const defaultMoneyDecimalPlaces = 2
+
+// bundledValidationTags are the Thai and number format tags registered by NewValidator
+var bundledValidationTags = []struct {
+	tag      string
+	fn       validator.Func
+	messages map[string]string
+}{
+	{"thai_id", ValidateThaiID, map[string]string{
+		"en": "{0} must be a valid Thai national ID",
+		"th": "{0} ต้องเป็นเลขประจำตัวประชาชนที่ถูกต้อง",
+	}},
+	{"thai_tax_id", ValidateThaiID, map[string]string{
+		"en": "{0} must be a valid Thai tax ID",
+		"th": "{0} ต้องเป็นเลขประจำตัวผู้เสียภาษีที่ถูกต้อง",
+	}},
+	{"thai_mobile", ValidateThaiMobile, map[string]string{
+		"en": "{0} must be a valid Thai mobile number",
+		"th": "{0} ต้องเป็นหมายเลขโทรศัพท์มือถือที่ถูกต้อง",
+	}},
+	{"promptpay", ValidatePromptPay, map[string]string{
+		"en": "{0} must be a valid PromptPay ID",
+		"th": "{0} ต้องเป็นหมายเลขพร้อมเพย์ที่ถูกต้อง",
+	}},
+	{"thai_bank_account", ValidateThaiBankAccount, map[string]string{
+		"en": "{0} must be a valid bank account number",
+		"th": "{0} ต้องเป็นเลขที่บัญชีธนาคารที่ถูกต้อง",
+	}},
+	{"thai_postal_code", ValidateThaiPostalCode, map[string]string{
+		"en": "{0} must be a valid Thai postal code",
+		"th": "{0} ต้องเป็นรหัสไปรษณีย์ที่ถูกต้อง",
+	}},
+	{"date_layout", ValidateDateLayout, map[string]string{
+		"en": "{0} must be a date in the format {1}",
+		"th": "{0} ต้องเป็นวันที่ในรูปแบบ {1}",
+	}},
+	{"decimal_places", ValidateDecimalPlaces, map[string]string{
+		"en": "{0} must have at most {1} decimal places",
+		"th": "{0} ต้องมีทศนิยมไม่เกิน {1} ตำแหน่ง",
+	}},
+	{"money_amount", ValidateMoneyAmount, map[string]string{
+		"en": "{0} must be a valid money amount",
+		"th": "{0} ต้องเป็นจำนวนเงินที่ถูกต้อง",
+	}},
+}
+
+// ValidateThaiID validates a 13 digit Thai national ID or tax ID using its check digit.
+// Dashes and spaces are ignored.
+func ValidateThaiID(fl validator.FieldLevel) bool {
+	return isThaiID(stripSeparators(fl.Field()))
+}
+
+// ValidateThaiMobile validates a Thai mobile number in the local or +66 format.
+// Dashes and spaces are ignored.
+func ValidateThaiMobile(fl validator.FieldLevel) bool {
+	return RegExpThaiMobile.MatchString(stripSeparators(fl.Field()))
+}
+
+// ValidatePromptPay validates a PromptPay ID, which is a Thai mobile number,
+// a national ID or tax ID, or a 15 digit e-wallet ID. Dashes and spaces are ignored.
+func ValidatePromptPay(fl validator.FieldLevel) bool {
+	value := stripSeparators(fl.Field())
+	switch {
+	case RegExpThaiMobile.MatchString(value):
+		return true
+	case len(value) == 13:
+		return isThaiID(value)
+	case len(value) == 15:
+		return regExpDigits.MatchString(value)
+	}
+	return false
+}
+
+// ValidateThaiBankAccount validates a Thai bank account number of 10 or 12 digits,
+// or of the length of the bank code in the tag parameter, e.g. thai_bank_account=gsb.
+// Dashes and spaces are ignored.
+func ValidateThaiBankAccount(fl validator.FieldLevel) bool {
+	value := stripSeparators(fl.Field())
+	if !regExpDigits.MatchString(value) {
+		return false
+	}
+	if param := strings.ToLower(fl.Param()); param != "" {
+		return len(value) == ThaiBankAccountLengths[param]
+	}
+	return len(value) == 10 || len(value) == 12
+}
+
+// ValidateThaiPostalCode validates a 5 digit Thai postal code
+func ValidateThaiPostalCode(fl validator.FieldLevel) bool {
+	value := stringValue(fl.Field())
+	if !RegExpThaiPostalCode.MatchString(value) {
+		return false
+	}
+	province, _ := strconv.Atoi(value[:2])
+	return province >= 10 && province <= 96
+}
+
+// ValidateDateLayout validates a date string using the time layout in the tag parameter,
+// e.g. date_layout=2006-01-02. Layouts cannot contain commas.
+func ValidateDateLayout(fl validator.FieldLevel) bool {
+	_, err := time.Parse(fl.Param(), stringValue(fl.Field()))
+	return err == nil
+}
+
+// ValidateDecimalPlaces validates that a number or number string has at most the number
+// of decimal places in the tag parameter, e.g. decimal_places=2
+func ValidateDecimalPlaces(fl validator.FieldLevel) bool {
+	places, err := strconv.Atoi(fl.Param())
+	if err != nil {
+		return false
+	}
+	decimals, ok := decimalPlaces(fl.Field())
+	return ok && decimals <= places
+}
+
+// ValidateMoneyAmount validates a non-negative number or number string, with optional
+// thousands separators, having at most 2 decimal places
+func ValidateMoneyAmount(fl validator.FieldLevel) bool {
+	field := fl.Field()
+	decimals, ok := decimalPlaces(field)
+	if !ok || decimals > defaultMoneyDecimalPlaces {
+		return false
+	}
+	switch field.Kind() {
+	case reflect.String:
+		return !strings.HasPrefix(field.String(), "-")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return field.Int() >= 0
+	case reflect.Float32, reflect.Float64:
+		return field.Float() >= 0
+	}
+	return true
+}
+
+// isThaiID validates the check digit of a 13 digit Thai ID
+func isThaiID(value string) bool {
+	if len(value) != 13 || !regExpDigits.MatchString(value) {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 12; i++ {
+		sum += int(value[i]-'0') * (13 - i)
+	}
+	return (11-sum%11)%10 == int(value[12]-'0')
+}
+
+// decimalPlaces returns the number of decimal places of a number or number string field
+func decimalPlaces(field reflect.Value) (int, bool) {
+	switch field.Kind() {
+	case reflect.String:
+		value := field.String()
+		if value == "" || !RegExpNumberString.MatchString(value) {
+			return 0, false
+		}
+		if _, decimals, ok := strings.Cut(value, "."); ok {
+			return len(decimals), true
+		}
+		return 0, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return 0, true
+	case reflect.Float32, reflect.Float64:
+		f := field.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return 0, false
+		}
+		bitSize := 64
+		if field.Kind() == reflect.Float32 {
+			bitSize = 32
+		}
+		if _, decimals, ok := strings.Cut(strconv.FormatFloat(f, 'f', -1, bitSize), "."); ok {
+			return len(decimals), true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// stringValue returns the value of a string field, or an empty string for other kinds
+func stringValue(field reflect.Value) string {
+	if field.Kind() != reflect.String {
+		return ""
+	}
+	return field.String()
+}
+
+// stripSeparators returns the value of a string field without dashes and spaces
+func stripSeparators(field reflect.Value) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(stringValue(field))
+}
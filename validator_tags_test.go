@@ -0,0 +1,93 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dollarsignteam/go-utils"
+)
+
+func TestValidateThaiTags(t *testing.T) {
+	tests := []struct {
+		Tag      string
+		Input    string
+		Expected bool
+	}{
+		{Tag: "thai_id", Input: "1103700221441", Expected: true},
+		{Tag: "thai_id", Input: "1-1037-00221-44-1", Expected: true},
+		{Tag: "thai_id", Input: "1103700221442", Expected: false},
+		{Tag: "thai_id", Input: "110370022144", Expected: false},
+		{Tag: "thai_tax_id", Input: "0105550001232", Expected: true},
+		{Tag: "thai_tax_id", Input: "010555000123x", Expected: false},
+		{Tag: "thai_mobile", Input: "0812345678", Expected: true},
+		{Tag: "thai_mobile", Input: "081-234-5678", Expected: true},
+		{Tag: "thai_mobile", Input: "+66912345678", Expected: true},
+		{Tag: "thai_mobile", Input: "021234567", Expected: false},
+		{Tag: "thai_mobile", Input: "0712345678", Expected: false},
+		{Tag: "promptpay", Input: "0612345678", Expected: true},
+		{Tag: "promptpay", Input: "1103700221441", Expected: true},
+		{Tag: "promptpay", Input: "123456789012345", Expected: true},
+		{Tag: "promptpay", Input: "1103700221440", Expected: false},
+		{Tag: "promptpay", Input: "12345", Expected: false},
+		{Tag: "thai_bank_account", Input: "123-4-56789-0", Expected: true},
+		{Tag: "thai_bank_account", Input: "123456789012", Expected: true},
+		{Tag: "thai_bank_account", Input: "12345678901", Expected: false},
+		{Tag: "thai_bank_account=gsb", Input: "123456789012", Expected: true},
+		{Tag: "thai_bank_account=kbank", Input: "123456789012", Expected: false},
+		{Tag: "thai_bank_account=unknown", Input: "1234567890", Expected: false},
+		{Tag: "thai_postal_code", Input: "10110", Expected: true},
+		{Tag: "thai_postal_code", Input: "96000", Expected: true},
+		{Tag: "thai_postal_code", Input: "99999", Expected: false},
+		{Tag: "thai_postal_code", Input: "1011", Expected: false},
+		{Tag: "date_layout=2006-01-02", Input: "2024-02-29", Expected: true},
+		{Tag: "date_layout=2006-01-02", Input: "2023-02-29", Expected: false},
+		{Tag: "date_layout=02/01/2006", Input: "31/12/2024", Expected: true},
+		{Tag: "timezone", Input: "UTC", Expected: true},
+		{Tag: "timezone", Input: "Mars/Olympus", Expected: false},
+		{Tag: "decimal_places=2", Input: "1,000.25", Expected: true},
+		{Tag: "decimal_places=2", Input: "10.255", Expected: false},
+		{Tag: "decimal_places=0", Input: "10", Expected: true},
+		{Tag: "decimal_places=2", Input: "abc", Expected: false},
+		{Tag: "money_amount", Input: "1,000.50", Expected: true},
+		{Tag: "money_amount", Input: "0", Expected: true},
+		{Tag: "money_amount", Input: "-1.00", Expected: false},
+		{Tag: "money_amount", Input: "1.005", Expected: false},
+	}
+	for _, test := range tests {
+		err := utils.Validate.Var(test.Input, test.Tag)
+		assert.Equal(t, test.Expected, err == nil, test.Tag+" "+test.Input)
+	}
+}
+
+func TestValidateNumberTags(t *testing.T) {
+	assert.NoError(t, utils.Validate.Var(12.5, "decimal_places=2"))
+	assert.Error(t, utils.Validate.Var(12.125, "decimal_places=2"))
+	assert.NoError(t, utils.Validate.Var(100, "money_amount"))
+	assert.Error(t, utils.Validate.Var(-100, "money_amount"))
+	assert.NoError(t, utils.Validate.Var(99.99, "money_amount"))
+	assert.Error(t, utils.Validate.Var(-0.5, "money_amount"))
+	assert.Error(t, utils.Validate.Var(12345, "thai_postal_code"))
+}
+
+func TestValidateThaiTags_Messages(t *testing.T) {
+	type Customer struct {
+		NationalID string  `json:"nationalId" validate:"thai_id"`
+		Mobile     string  `json:"mobile" validate:"thai_mobile"`
+		Balance    float64 `json:"balance" validate:"decimal_places=2"`
+		BirthDate  string  `json:"birthDate" validate:"date_layout=2006-01-02"`
+		TimeZone   string  `json:"timeZone" validate:"timezone"`
+	}
+	customer := Customer{NationalID: "1", Mobile: "1", Balance: 1.001, BirthDate: "01/01/2000", TimeZone: "x"}
+	validationErr := utils.ParseValidationError(utils.ValidateStruct(customer))
+	assert.Equal(t, map[string]string{
+		"nationalId": "nationalId must be a valid Thai national ID",
+		"mobile":     "mobile must be a valid Thai mobile number",
+		"balance":    "balance must have at most 2 decimal places",
+		"birthDate":  "birthDate must be a date in the format 2006-01-02",
+		"timeZone":   "timeZone must be a valid time zone",
+	}, messagesOf(validationErr.Details))
+	translated := utils.TranslateValidationError(validationErr, "th")
+	assert.Equal(t, "balance ต้องมีทศนิยมไม่เกิน 2 ตำแหน่ง", messagesOf(translated.Details)["balance"])
+	assert.Equal(t, "timeZone ต้องเป็นเขตเวลาที่ถูกต้อง", messagesOf(translated.Details)["timeZone"])
+}
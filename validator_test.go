@@ -1,9 +1,12 @@
 package utils_test
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
+	"github.com/go-playground/validator/v10"
+
 	"github.com/stretchr/testify/assert"
 
 	"github.com/dollarsignteam/go-utils"
@@ -71,6 +74,192 @@ func TestValidateStruct_Invalid(t *testing.T) {
 	assert.EqualError(t, err, "Validation failed for 'Balance'")
 }
 
+type Member struct {
+	Name      string    `json:"name" validate:"required,min=3" message:"Please enter a name with at least {1} characters"`
+	Email     string    `json:"email" validate:"required,email"`
+	Age       int       `json:"age" validate:"gte=18"`
+	Tags      []string  `json:"tags" validate:"max=2"`
+	Addresses []Address `json:"addresses" validate:"dive"`
+}
+
+type Address struct {
+	City string `json:"city" validate:"required" message:"City is required"`
+	Zip  string `json:"zip" validate:"len=5"`
+}
+
+func messagesOf(details []utils.ValidationErrorDetail) map[string]string {
+	messages := map[string]string{}
+	for _, detail := range details {
+		messages[detail.Field] = detail.Message
+	}
+	return messages
+}
+
+func TestValidateStruct_Messages(t *testing.T) {
+	member := Member{
+		Name:      "Al",
+		Email:     "invalid",
+		Age:       16,
+		Tags:      []string{"a", "b", "c"},
+		Addresses: []Address{{Zip: "123"}},
+	}
+	err := utils.ValidateStruct(member)
+	validationErr := utils.ParseValidationError(err)
+	assert.Equal(t, map[string]string{
+		"name":  "Please enter a name with at least 3 characters",
+		"email": "email must be a valid email address",
+		"age":   "age must be 18 or greater",
+		"tags":  "tags must contain at maximum 2 items",
+		"city":  "City is required",
+		"zip":   "zip must be 5 characters in length",
+	}, messagesOf(validationErr.Details))
+
+	translated := utils.TranslateValidationError(validationErr, "th-TH,th;q=0.9")
+	assert.Equal(t, map[string]string{
+		"name":  "Please enter a name with at least 3 characters",
+		"email": "email ต้องเป็นอีเมลที่ถูกต้อง",
+		"age":   "age ต้องมีค่ามากกว่าหรือเท่ากับ 18",
+		"tags":  "tags ต้องมีไม่เกิน 2 รายการ",
+		"city":  "City is required",
+		"zip":   "zip ต้องมีความยาว 5 ตัวอักษร",
+	}, messagesOf(translated.Details))
+	assert.Equal(t, "email must be a valid email address", messagesOf(validationErr.Details)["email"])
+	assert.Equal(t, validationErr.Details, utils.TranslateValidationError(validationErr, "fr").Details)
+
+	resp := utils.ParseErrorResponse(utils.NewCommonErrorBadRequest(err), "th")
+	assert.Equal(t, "email ต้องเป็นอีเมลที่ถูกต้อง", messagesOf(resp.ErrorValidation)["email"])
+	resp = utils.ParseErrorResponse(utils.ValidateStruct(Data{Balance: "x"}), "th")
+	assert.Equal(t, "Balance ต้องเป็นตัวเลขที่ถูกต้อง", resp.ErrorValidation[0].Message)
+}
+
+func TestRegisterValidationTag(t *testing.T) {
+	type Order struct {
+		Code string `validate:"order_code"`
+	}
+	err := utils.RegisterValidationTag("order_code", func(fl validator.FieldLevel) bool {
+		return len(fl.Field().String()) == 6
+	}, map[string]string{
+		"en": "{0} must be a valid order code",
+		"th": "{0} ต้องเป็นรหัสคำสั่งซื้อที่ถูกต้อง",
+	})
+	assert.NoError(t, err)
+	validationErr := utils.ParseValidationError(utils.ValidateStruct(Order{Code: "A1"}))
+	assert.Equal(t, "Code must be a valid order code", validationErr.Details[0].Message)
+	translated := utils.TranslateValidationError(validationErr, "th")
+	assert.Equal(t, "Code ต้องเป็นรหัสคำสั่งซื้อที่ถูกต้อง", translated.Details[0].Message)
+
+	err = utils.RegisterValidationTranslation("order_code", map[string]string{"xx": "{0}"})
+	assert.True(t, errors.Is(err, utils.ErrValidationLanguageUnsupported))
+
+	type Unknown struct {
+		Value string `validate:"unknown_tag"`
+	}
+	_ = utils.Validate.RegisterValidation("unknown_tag", func(validator.FieldLevel) bool { return false })
+	validationErr = utils.ParseValidationError(utils.ValidateStruct(Unknown{}))
+	assert.Equal(t, "Key: 'Unknown.Value', Error: Validation for 'Value' failed on the 'unknown_tag' tag", validationErr.Details[0].Message)
+}
+
+type Order struct {
+	Items    []OrderItem `json:"items" validate:"dive"`
+	Password string      `json:"password" validate:"min=8" sensitive:"true"`
+}
+
+type OrderItem struct {
+	Name  string `json:"name" validate:"required"`
+	Price int    `json:"price" validate:"gt=0"`
+}
+
+func TestValidateStruct_Paths(t *testing.T) {
+	order := Order{
+		Items:    []OrderItem{{Name: "a", Price: 1}, {Name: "b", Price: 2}, {Name: "c", Price: -1}},
+		Password: "secret",
+	}
+	validationErr := utils.ParseValidationError(utils.ValidateStruct(order))
+	assert.Equal(t, []utils.ValidationErrorDetail{
+		{
+			Field:   "price",
+			Path:    "items[2].price",
+			Tag:     "gt",
+			Param:   "0",
+			Value:   -1,
+			Message: "price must be greater than 0",
+		},
+		{
+			Field:   "password",
+			Path:    "password",
+			Tag:     "min",
+			Param:   "8",
+			Value:   utils.ValidationRedactedValue,
+			Message: "password must be at least 8 characters in length",
+		},
+	}, validationErr.Details)
+
+	rawErr := utils.ParseValidationError(utils.Validate.Struct(order))
+	assert.Equal(t, "items[2].price", rawErr.Details[0].Path)
+	assert.Nil(t, rawErr.Details[1].Value)
+
+	var items []OrderItem
+	err := utils.JSON.ParseAndValidate(`[{"name":"a","price":1},{"price":5}]`, &items)
+	validationErr = utils.ParseValidationError(err)
+	assert.Len(t, validationErr.Details, 1)
+	assert.Equal(t, "[1].name", validationErr.Details[0].Path)
+	assert.Equal(t, "", validationErr.Details[0].Value)
+}
+
+func TestNewValidator(t *testing.T) {
+	type Account struct {
+		Code string `json:"code" form:"account_code" validate:"account_code"`
+	}
+	first := utils.NewValidator(utils.ValidatorConfig{})
+	second := utils.NewValidator(utils.ValidatorConfig{
+		TagNameFunc: func(field reflect.StructField) string {
+			return field.Tag.Get("form")
+		},
+	})
+	assert.NoError(t, first.RegisterTag("account_code", func(fl validator.FieldLevel) bool {
+		return fl.Field().String() == "A"
+	}, map[string]string{"en": "{0} must be account A"}))
+	assert.NoError(t, second.RegisterTag("account_code", func(fl validator.FieldLevel) bool {
+		return fl.Field().String() == "B"
+	}, map[string]string{"en": "{0} must be account B", "th": "{0} ต้องเป็นบัญชี B"}))
+
+	assert.NoError(t, first.Struct(Account{Code: "A"}))
+	validationErr := first.ParseError(first.Struct(Account{Code: "B"}))
+	assert.Equal(t, "code must be account A", validationErr.Details[0].Message)
+
+	assert.NoError(t, second.Struct(Account{Code: "B"}))
+	validationErr = second.ParseError(second.Struct(Account{Code: "A"}))
+	assert.Equal(t, "account_code", validationErr.Details[0].Field)
+	assert.Equal(t, "account_code must be account B", validationErr.Details[0].Message)
+	assert.Equal(t, "account_code ต้องเป็นบัญชี B", second.Translate(validationErr, "th").Details[0].Message)
+
+	assert.Panics(t, func() { _ = utils.ValidateStruct(Account{Code: "A"}) })
+	assert.NoError(t, first.Instance().Var("1,000", "number_string"))
+}
+
+func TestNewValidator_SkipBundledTags(t *testing.T) {
+	v := utils.NewValidator(utils.ValidatorConfig{SkipBundledTags: true})
+	assert.Panics(t, func() { _ = v.Instance().Var("1,000", "number_string") })
+	validationErr := v.ParseError(v.Struct(struct {
+		Name string `json:"name" validate:"required"`
+	}{}))
+	assert.Equal(t, "name is a required field", validationErr.Details[0].Message)
+	assert.Equal(t, "name จำเป็นต้องระบุ", v.Translate(validationErr, "th").Details[0].Message)
+}
+
+func TestJsonUtil_Validator(t *testing.T) {
+	v := utils.NewValidator(utils.ValidatorConfig{})
+	assert.NoError(t, v.RegisterTag("ok_only", func(fl validator.FieldLevel) bool {
+		return fl.Field().String() == "ok"
+	}, nil))
+	jsonUtil := utils.JsonUtil{Validator: v}
+	var result struct {
+		Data string `json:"data" validate:"ok_only"`
+	}
+	assert.NoError(t, jsonUtil.ParseAndValidate(`{"data":"ok"}`, &result))
+	assert.EqualError(t, jsonUtil.ParseAndValidate(`{"data":"no"}`, &result), "Validation failed for 'data'")
+}
+
 func BenchmarkValidateNumberString(b *testing.B) {
 	data := Data{Balance: "10,000.00"}
 	for n := 0; n < b.N; n++ {
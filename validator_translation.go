@@ -0,0 +1,238 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	"golang.org/x/text/language"
+)
+
+// DefaultValidationLanguage is the language of validation messages when no requested language is supported
+const DefaultValidationLanguage = "en"
+
+// ValidationMessageTag is the struct tag overriding the validation messages of a field.
+// {0} is replaced by the field name and {1} by the parameter of the failed tag.
+const ValidationMessageTag = "message"
+
+// ErrValidationLanguageUnsupported is returned when registering messages in a language without a translator
+var ErrValidationLanguageUnsupported = errors.New("unsupported validation language")
+
+// ValidationTranslator is the universal translator of the validation messages of DefaultValidator,
+// supporting English and Thai. Other languages can be added with AddTranslator before registering their messages.
+var ValidationTranslator = DefaultValidator.Translator()
+
+// thaiValidationTranslation is the Thai message of a validation tag, with optional
+// messages for the length of strings and the number of items of collections
+type thaiValidationTranslation struct {
+	tag   string // The validation tag
+	text  string // The message for numbers and other kinds
+	str   string // The message for strings, defaults to text
+	items string // The message for slices, arrays and maps, defaults to text
+}
+
+// thaiValidationTranslations are the Thai messages of the common validation tags
+var thaiValidationTranslations = []thaiValidationTranslation{
+	{tag: "required", text: "{0} จำเป็นต้องระบุ"},
+	{tag: "required_if", text: "{0} จำเป็นต้องระบุ"},
+	{tag: "required_unless", text: "{0} จำเป็นต้องระบุ"},
+	{tag: "required_with", text: "{0} จำเป็นต้องระบุ"},
+	{tag: "required_with_all", text: "{0} จำเป็นต้องระบุ"},
+	{tag: "required_without", text: "{0} จำเป็นต้องระบุ"},
+	{tag: "required_without_all", text: "{0} จำเป็นต้องระบุ"},
+	{tag: "len", text: "{0} ต้องมีค่าเท่ากับ {1}", str: "{0} ต้องมีความยาว {1} ตัวอักษร", items: "{0} ต้องมี {1} รายการ"},
+	{tag: "min", text: "{0} ต้องมีค่าอย่างน้อย {1}", str: "{0} ต้องมีความยาวอย่างน้อย {1} ตัวอักษร", items: "{0} ต้องมีอย่างน้อย {1} รายการ"},
+	{tag: "max", text: "{0} ต้องมีค่าไม่เกิน {1}", str: "{0} ต้องมีความยาวไม่เกิน {1} ตัวอักษร", items: "{0} ต้องมีไม่เกิน {1} รายการ"},
+	{tag: "eq", text: "{0} ต้องมีค่าเท่ากับ {1}"},
+	{tag: "ne", text: "{0} ต้องมีค่าไม่เท่ากับ {1}"},
+	{tag: "gt", text: "{0} ต้องมีค่ามากกว่า {1}", str: "{0} ต้องมีความยาวมากกว่า {1} ตัวอักษร", items: "{0} ต้องมีมากกว่า {1} รายการ"},
+	{tag: "gte", text: "{0} ต้องมีค่ามากกว่าหรือเท่ากับ {1}", str: "{0} ต้องมีความยาวอย่างน้อย {1} ตัวอักษร", items: "{0} ต้องมีอย่างน้อย {1} รายการ"},
+	{tag: "lt", text: "{0} ต้องมีค่าน้อยกว่า {1}", str: "{0} ต้องมีความยาวน้อยกว่า {1} ตัวอักษร", items: "{0} ต้องมีน้อยกว่า {1} รายการ"},
+	{tag: "lte", text: "{0} ต้องมีค่าน้อยกว่าหรือเท่ากับ {1}", str: "{0} ต้องมีความยาวไม่เกิน {1} ตัวอักษร", items: "{0} ต้องมีไม่เกิน {1} รายการ"},
+	{tag: "eqfield", text: "{0} ต้องตรงกับ {1}"},
+	{tag: "nefield", text: "{0} ต้องไม่ตรงกับ {1}"},
+	{tag: "oneof", text: "{0} ต้องเป็นค่าใดค่าหนึ่งใน [{1}]"},
+	{tag: "email", text: "{0} ต้องเป็นอีเมลที่ถูกต้อง"},
+	{tag: "url", text: "{0} ต้องเป็น URL ที่ถูกต้อง"},
+	{tag: "uri", text: "{0} ต้องเป็น URI ที่ถูกต้อง"},
+	{tag: "uuid", text: "{0} ต้องเป็น UUID ที่ถูกต้อง"},
+	{tag: "uuid4", text: "{0} ต้องเป็น UUID เวอร์ชัน 4 ที่ถูกต้อง"},
+	{tag: "ip", text: "{0} ต้องเป็น IP address ที่ถูกต้อง"},
+	{tag: "alpha", text: "{0} ต้องเป็นตัวอักษรเท่านั้น"},
+	{tag: "alphanum", text: "{0} ต้องเป็นตัวอักษรหรือตัวเลขเท่านั้น"},
+	{tag: "numeric", text: "{0} ต้องเป็นตัวเลข"},
+	{tag: "number", text: "{0} ต้องเป็นตัวเลข"},
+	{tag: "boolean", text: "{0} ต้องเป็นค่าความจริง"},
+	{tag: "datetime", text: "{0} ต้องอยู่ในรูปแบบ {1}"},
+	{tag: "json", text: "{0} ต้องเป็น JSON ที่ถูกต้อง"},
+	{tag: "e164", text: "{0} ต้องเป็นหมายเลขโทรศัพท์ในรูปแบบ E.164"},
+}
+
+// registerDefaultTranslations registers the English and Thai messages of the common validation tags
+func (v *Validator) registerDefaultTranslations() {
+	enTranslator, _ := v.translator.GetTranslator("en")
+	_ = en_translations.RegisterDefaultTranslations(v.validate, enTranslator)
+	thTranslator, _ := v.translator.GetTranslator("th")
+	for _, t := range thaiValidationTranslations {
+		_ = v.validate.RegisterTranslation(t.tag, thTranslator, t.register, t.translate)
+	}
+}
+
+// register adds the messages of the Thai translation to the translator
+func (t thaiValidationTranslation) register(trans ut.Translator) error {
+	if err := trans.Add(t.tag, t.text, true); err != nil {
+		return err
+	}
+	if t.str != "" {
+		if err := trans.Add(t.tag+"-string", t.str, true); err != nil {
+			return err
+		}
+	}
+	if t.items != "" {
+		return trans.Add(t.tag+"-items", t.items, true)
+	}
+	return nil
+}
+
+// translate returns the Thai message of the field error by the kind of the field
+func (t thaiValidationTranslation) translate(trans ut.Translator, fe validator.FieldError) string {
+	key := t.tag
+	switch fe.Kind() {
+	case reflect.String:
+		if t.str != "" {
+			key += "-string"
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if t.items != "" {
+			key += "-items"
+		}
+	}
+	message, err := trans.T(key, fe.Field(), fe.Param())
+	if err != nil {
+		return fe.Error()
+	}
+	return message
+}
+
+// RegisterTranslation registers the messages of a validation tag by language,
+// e.g. {"en": "{0} must be a valid number"}, replacing previous messages of the tag.
+// {0} is replaced by the field name and {1} by the tag parameter.
+// It is not safe for concurrent use with validation and should be called at startup.
+func (v *Validator) RegisterTranslation(tag string, messages map[string]string) error {
+	for lang, message := range messages {
+		trans, ok := v.translator.GetTranslator(lang)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrValidationLanguageUnsupported, lang)
+		}
+		register := func(trans ut.Translator) error {
+			return trans.Add(tag, message, true)
+		}
+		if err := v.validate.RegisterTranslation(tag, trans, register, translateValidationTag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterTag registers a custom validation tag together with its messages by language
+func (v *Validator) RegisterTag(tag string, fn validator.Func, messages map[string]string) error {
+	if err := v.validate.RegisterValidation(tag, fn); err != nil {
+		return err
+	}
+	return v.RegisterTranslation(tag, messages)
+}
+
+// Translate translates the messages of the validation error details into the best matching
+// language of the Accept-Language value. Messages overridden by the ValidationMessageTag
+// struct tag or set by async validations are kept as is.
+func (v *Validator) Translate(err ValidationError, acceptLanguage string) ValidationError {
+	trans := v.findTranslator(acceptLanguage)
+	details := make([]ValidationErrorDetail, len(err.Details))
+	copy(details, err.Details)
+	for i, fe := range err.Errors {
+		if i < len(details) && details[i].Message == v.message(fe, nil) {
+			details[i].Message = v.message(fe, trans)
+		}
+	}
+	for i := len(err.Errors); i < len(details); i++ {
+		if details[i].Message == v.tagMessage(nil, details[i]) {
+			details[i].Message = v.tagMessage(trans, details[i])
+		}
+	}
+	err.Details = details
+	return err
+}
+
+// RegisterValidationTranslation registers the messages of a validation tag by language with DefaultValidator
+func RegisterValidationTranslation(tag string, messages map[string]string) error {
+	return DefaultValidator.RegisterTranslation(tag, messages)
+}
+
+// RegisterValidationTag registers a custom validation tag together with its messages by language with DefaultValidator
+func RegisterValidationTag(tag string, fn validator.Func, messages map[string]string) error {
+	return DefaultValidator.RegisterTag(tag, fn, messages)
+}
+
+// TranslateValidationError translates the messages of the validation error details into the best
+// matching language of the Accept-Language value using the Validator that produced the error,
+// or DefaultValidator for errors created otherwise.
+func TranslateValidationError(err ValidationError, acceptLanguage string) ValidationError {
+	return validatorOrDefault(err.validator).Translate(err, acceptLanguage)
+}
+
+// translateValidationTag translates the field error using the message registered for its tag
+func translateValidationTag(trans ut.Translator, fe validator.FieldError) string {
+	message, err := trans.T(fe.Tag(), fe.Field(), fe.Param())
+	if err != nil {
+		return fe.Error()
+	}
+	return message
+}
+
+// message returns the message of the field error in the language of the translator,
+// falling back to English and then to a generic message for tags without messages
+func (v *Validator) message(fe validator.FieldError, trans ut.Translator) string {
+	fallback := v.translator.GetFallback()
+	if trans == nil {
+		trans = fallback
+	}
+	if message := fe.Translate(trans); message != fe.Error() {
+		return message
+	}
+	if message := fe.Translate(fallback); message != fe.Error() {
+		return message
+	}
+	return fmt.Sprintf(errMessageValidationFailed, fe.StructNamespace(), fe.Field(), fe.Tag())
+}
+
+// tagMessage returns the message registered for the tag of a validation error detail
+// in the language of the translator, falling back to English and then to a generic message
+func (v *Validator) tagMessage(trans ut.Translator, detail ValidationErrorDetail) string {
+	fallback := v.translator.GetFallback()
+	if trans == nil {
+		trans = fallback
+	}
+	for _, t := range []ut.Translator{trans, fallback} {
+		if message, err := t.T(detail.Tag, detail.Field, detail.Param); err == nil {
+			return message
+		}
+	}
+	return fmt.Sprintf("Validation for '%s' failed on the '%s' tag", detail.Field, detail.Tag)
+}
+
+// findTranslator returns the translator of the best matching language of the
+// Accept-Language value, or the translator of DefaultValidationLanguage
+func (v *Validator) findTranslator(acceptLanguage string) ut.Translator {
+	tags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+	locales := make([]string, 0, len(tags)*2)
+	for _, tag := range tags {
+		base, _ := tag.Base()
+		locales = append(locales, strings.ReplaceAll(tag.String(), "-", "_"), base.String())
+	}
+	trans, _ := v.translator.FindTranslator(locales...)
+	return trans
+}